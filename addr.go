@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"context"
+	"math/big"
 	"net"
 )
 
@@ -9,8 +11,14 @@ func NewAddrWithPort(ip, port string) *Addr {
 }
 
 func NewAddr(s string) *Addr {
-	if ip, port, err := net.SplitHostPort(s); err == nil {
-		return &Addr{ParseIP(ip), port}
+	return newAddr(s, nil)
+}
+
+func newAddr(s string, resolver []Resolver) *Addr {
+	if host, port, err := net.SplitHostPort(s); err == nil {
+		if ip := ParseIP(host, resolver...); ip != nil {
+			return &Addr{ip, port}
+		}
 	}
 	return nil
 }
@@ -34,18 +42,42 @@ func NewAddrs(ss []string) Addrs {
 	return addrs
 }
 
-func NewAddrsWithDefaultPort(ss []string, port string) Addrs {
+// NewAddrsWithDefaultPort parse ip:port or ip with default port, hostname will be resolved by the optional resolver or DefaultResolver
+func NewAddrsWithDefaultPort(ss []string, port string, resolver ...Resolver) Addrs {
 	var addrs Addrs
 	for _, s := range ss {
-		if addr := NewAddr(s); addr != nil {
+		if addr := newAddr(s, resolver); addr != nil {
 			addrs = append(addrs, addr)
-		} else if ip := ParseIP(s); ip != nil {
+		} else if ip := ParseIP(s, resolver...); ip != nil {
 			addrs = append(addrs, &Addr{ip, port})
 		}
 	}
 	return addrs
 }
 
+// NewAddrsFanOut like NewAddrsWithDefaultPort, but every A/AAAA record of hostname becomes an Addr
+func NewAddrsFanOut(ss []string, port string, resolver ...Resolver) Addrs {
+	var addrs Addrs
+	for _, s := range ss {
+		host, p := s, port
+		if h, sp, err := net.SplitHostPort(s); err == nil {
+			host, p = h, sp
+		}
+		if ip := ParseIP(host, NoDNS); ip != nil {
+			addrs = append(addrs, &Addr{ip, p})
+			continue
+		}
+		ips, err := ParseHostToIPs(host, resolver...)
+		if err != nil {
+			continue
+		}
+		for _, ip := range ips {
+			addrs = append(addrs, &Addr{ip, p})
+		}
+	}
+	return addrs
+}
+
 type Addrs []*Addr
 
 func NewAddrsWithPorts(ips []string, ports interface{}) *AddrsGenerator {
@@ -62,32 +94,32 @@ type AddrsGenerator struct {
 	Ports []string
 }
 
-func (as AddrsGenerator) Count() int {
-	return len(as.IPs) * len(as.Ports)
+func (as AddrsGenerator) Count() *big.Int {
+	return new(big.Int).Mul(big.NewInt(int64(len(as.IPs))), big.NewInt(int64(len(as.Ports))))
 }
 
+// GenerateWithIP iterate all ports of an ip, then next ip
 func (as AddrsGenerator) GenerateWithIP() chan *Addr {
-	gen := make(chan *Addr)
-	go func() {
-		for _, ip := range as.IPs {
-			for _, port := range as.Ports {
-				gen <- &Addr{ip, port}
-			}
-		}
-		close(gen)
-	}()
-	return gen
+	return as.GenerateWithIPContext(context.Background())
 }
 
+func (as AddrsGenerator) GenerateWithIPContext(ctx context.Context) chan *Addr {
+	return RangeAddrIterator(ctx, as.IteratorWithIP())
+}
+
+// GenerateWithPort iterate all ips of a port, then next port
 func (as AddrsGenerator) GenerateWithPort() chan *Addr {
-	gen := make(chan *Addr)
-	go func() {
-		for _, port := range as.Ports {
-			for _, ip := range as.IPs {
-				gen <- &Addr{ip, port}
-			}
-		}
-		close(gen)
-	}()
-	return gen
+	return as.GenerateWithPortContext(context.Background())
+}
+
+func (as AddrsGenerator) GenerateWithPortContext(ctx context.Context) chan *Addr {
+	return RangeAddrIterator(ctx, as.IteratorWithPort())
+}
+
+func (as AddrsGenerator) IteratorWithIP() *AddrsIterator {
+	return &AddrsIterator{ips: as.IPs, ports: as.Ports}
+}
+
+func (as AddrsGenerator) IteratorWithPort() *AddrsIterator {
+	return &AddrsIterator{ips: as.IPs, ports: as.Ports, byPort: true}
 }
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"github.com/chainreactors/utils/iputils"
+	"math/big"
+	"net"
+	"sort"
+)
+
+// AggregateOptions options of IPs.Aggregate
+type AggregateOptions struct {
+	// IPv4Prefix bucket prefix length of ipv4, 24 if 0
+	IPv4Prefix int
+	// IPv6Prefix bucket prefix length of ipv6, 64 if 0
+	IPv6Prefix int
+	// MinHosts collapse bucket only if it has at least MinHosts distinct ips,
+	// otherwise ips of bucket are kept and only adjacent ones are merged. <= 1 means always collapse
+	MinHosts int
+	// Tight collapse to the smallest prefix spanning all ips of bucket instead of the whole bucket
+	Tight bool
+}
+
+// Aggregate an aggregated cidr and its density
+type Aggregate struct {
+	CIDR *CIDR
+	// Hosts count of distinct input ips in CIDR
+	Hosts int
+	// Coverage Hosts / count of CIDR
+	Coverage float64
+	// Waste 1 - Coverage, ratio of addresses in CIDR that are not in input
+	Waste float64
+}
+
+func newAggregate(c *CIDR, hosts int) *Aggregate {
+	coverage, _ := new(big.Rat).SetFrac(big.NewInt(int64(hosts)), c.Count()).Float64()
+	return &Aggregate{CIDR: c, Hosts: hosts, Coverage: coverage, Waste: 1 - coverage}
+}
+
+type Aggregates []*Aggregate
+
+func (as Aggregates) CIDRs() CIDRs {
+	cs := make(CIDRs, len(as))
+	for i, a := range as {
+		cs[i] = a.CIDR
+	}
+	return cs
+}
+
+// Coverage overall coverage of all aggregates
+func (as Aggregates) Coverage() float64 {
+	hosts, count := new(big.Int), new(big.Int)
+	for _, a := range as {
+		hosts.Add(hosts, big.NewInt(int64(a.Hosts)))
+		count.Add(count, a.CIDR.Count())
+	}
+	if count.Sign() == 0 {
+		return 0
+	}
+	coverage, _ := new(big.Rat).SetFrac(hosts, count).Float64()
+	return coverage
+}
+
+type aggregateBucket struct {
+	cidr  *CIDR
+	hosts IPs
+}
+
+// Aggregate group ips by bucket prefix of each family, collapse dense buckets to cidr,
+// e.g. collapse to /24 if >= 8 ips: Aggregate(AggregateOptions{IPv4Prefix: 24, MinHosts: 8}).
+// result is sorted, ipv4 first
+func (is IPs) Aggregate(opt AggregateOptions) Aggregates {
+	if opt.IPv4Prefix == 0 {
+		opt.IPv4Prefix = 24
+	}
+	if opt.IPv6Prefix == 0 {
+		opt.IPv6Prefix = 64
+	}
+
+	var order []*aggregateBucket
+	buckets := make(map[string]*aggregateBucket)
+	seen := make(map[string]bool)
+	for _, ip := range is {
+		if ip == nil || seen[ip.String()] {
+			continue
+		}
+		seen[ip.String()] = true
+		prefix := opt.IPv4Prefix
+		if ip.Ver == IPV6 {
+			prefix = opt.IPv6Prefix
+		}
+		bucket := ip.Mask(prefix).CIDR(prefix)
+		b, ok := buckets[bucket.String()]
+		if !ok {
+			b = &aggregateBucket{cidr: bucket}
+			buckets[bucket.String()] = b
+			order = append(order, b)
+		}
+		b.hosts = append(b.hosts, ip)
+	}
+
+	var as Aggregates
+	for _, b := range order {
+		if len(b.hosts) >= opt.MinHosts {
+			c := b.cidr
+			if opt.Tight {
+				c = spanningCIDR(b.hosts, b.cidr.Mask)
+			}
+			as = append(as, newAggregate(c, len(b.hosts)))
+			continue
+		}
+
+		nets := make([]*net.IPNet, len(b.hosts))
+		for i, ip := range b.hosts {
+			nets[i] = ip.CIDR(ip.Len() * 8).Net()
+		}
+		v4, v6 := iputils.CoalesceCIDRs(nets)
+		for _, n := range append(v4, v6...) {
+			c := NewCIDRFromNet(n)
+			as = append(as, newAggregate(c, int(c.Count().Int64())))
+		}
+	}
+
+	sort.Slice(as, func(i, j int) bool {
+		if as[i].CIDR.Ver != as[j].CIDR.Ver {
+			return as[i].CIDR.Ver < as[j].CIDR.Ver
+		}
+		return as[i].CIDR.Compare(as[j].CIDR) < 0
+	})
+	return as
+}
+
+// spanningCIDR the smallest cidr containing all ips, mask will not be less than minMask
+func spanningCIDR(ips IPs, minMask int) *CIDR {
+	first, bits := ipBytes(ips[0]), ips[0].Len()*8
+	mask := bits
+	for _, ip := range ips[1:] {
+		other := ipBytes(ip)
+		common := 0
+		for common < mask && ipBit(first, common) == ipBit(other, common) {
+			common++
+		}
+		mask = common
+	}
+	if mask < minMask {
+		mask = minMask
+	}
+	return ips[0].Mask(mask).CIDR(mask)
+}
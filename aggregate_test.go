@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIPs_Aggregate(t *testing.T) {
+	ips := ParseIPs([]string{
+		"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.200", "10.0.0.1",
+		"10.0.1.1", "10.0.1.2",
+		"2001:db8::1", "2001:db8::2", "2001:db8:0:1::1",
+	})
+
+	as := ips.Aggregate(AggregateOptions{IPv4Prefix: 24, IPv6Prefix: 64, MinHosts: 5})
+	assert.Equal(t, []string{"10.0.0.0/24", "10.0.1.1/32", "10.0.1.2/32", "2001:db8::1/128", "2001:db8::2/128", "2001:db8:0:1::1/128"}, as.CIDRs().Strings())
+	assert.Equal(t, 5, as[0].Hosts)
+	assert.InDelta(t, 5.0/256, as[0].Coverage, 1e-9)
+	assert.InDelta(t, 1-5.0/256, as[0].Waste, 1e-9)
+	assert.Equal(t, 1.0, as[1].Coverage)
+
+	as = ips.Aggregate(AggregateOptions{IPv4Prefix: 16, IPv6Prefix: 48, Tight: true})
+	assert.Equal(t, []string{"10.0.0.0/23", "2001:db8::/63"}, as.CIDRs().Strings())
+	assert.Equal(t, 7, as[0].Hosts)
+	assert.Equal(t, 3, as[1].Hosts)
+	assert.InDelta(t, 10.0/(512+2*1<<64), as.Coverage(), 1e-9)
+
+	// adjacent ips of sparse bucket are merged
+	as = ParseIPs([]string{"10.0.0.4", "10.0.0.5", "10.0.0.6", "10.0.0.7"}).Aggregate(AggregateOptions{MinHosts: 8})
+	assert.Equal(t, []string{"10.0.0.4/30"}, as.CIDRs().Strings())
+	assert.Equal(t, 4, as[0].Hosts)
+}
+
+func TestIPs_Approx(t *testing.T) {
+	ips := ParseIPs([]string{"10.0.0.0", "10.0.0.128", "10.0.0.1", "192.168.1.5", "2001:db8::1", "2001:db8::10"})
+	assert.Equal(t, []string{"10.0.0.0/24", "192.168.1.5/32", "2001:db8::/123"}, ips.Approx().Strings())
+}
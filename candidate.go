@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// HostIterator iterate host identifiers, the host part of address without prefix
+type HostIterator interface {
+	Next() (*big.Int, bool)
+}
+
+// IPv6Strategy source of candidate host identifiers, Hosts return a new iterator every time
+type IPv6Strategy interface {
+	Hosts() HostIterator
+}
+
+// boundedStrategy strategy knowing its host identifiers need at least some host bits,
+// so hosts are not generated and discarded one by one for a small cidr
+type boundedStrategy interface {
+	hostsIn(hostBits int) HostIterator
+}
+
+// DefaultHexWords wordy hex patterns commonly configured by hand
+var DefaultHexWords = []string{
+	"dead:beef", "cafe:babe", "face:b00c", "feed:face", "dead:c0de", "bad:c0de", "c0ff:ee",
+	"cafe", "babe", "beef", "face", "c0de", "f00d", "dead", "abcd", "1337", "1111", "aaaa", "ffff",
+}
+
+// DefaultIPv6Strategies low byte, common service ports and wordy hex patterns
+func DefaultIPv6Strategies() []IPv6Strategy {
+	return []IPv6Strategy{
+		LowByteStrategy(0xff),
+		PortStrategy([]string{"21", "22", "25", "53", "80", "110", "143", "443", "445", "993", "995", "1433", "3306", "3389", "5432", "6379", "8000", "8080", "8443", "9200"}),
+		WordyStrategy(DefaultHexWords),
+	}
+}
+
+// sliceHosts iterate fixed host identifiers
+type sliceHosts struct {
+	hosts []*big.Int
+	index int
+}
+
+func (it *sliceHosts) Next() (*big.Int, bool) {
+	if it.index >= len(it.hosts) {
+		return nil, false
+	}
+	it.index++
+	return it.hosts[it.index-1], true
+}
+
+type hostsStrategy []*big.Int
+
+func (s hostsStrategy) Hosts() HostIterator {
+	return &sliceHosts{hosts: s}
+}
+
+// LowByteStrategy ::1 to ::max
+func LowByteStrategy(max int) IPv6Strategy {
+	hosts := make(hostsStrategy, 0, max)
+	for i := 1; i <= max; i++ {
+		hosts = append(hosts, big.NewInt(int64(i)))
+	}
+	return hosts
+}
+
+// PortStrategy service ports embedded as hex, both decimal digits written as hex (::443) and the value (::1bb)
+func PortStrategy(ports []string) IPv6Strategy {
+	var hosts hostsStrategy
+	for _, port := range ports {
+		n, err := parsePortNumber(port)
+		if err != nil {
+			continue
+		}
+		if len(port) <= 4 {
+			if v, err := strconv.ParseUint(port, 16, 16); err == nil {
+				hosts = append(hosts, new(big.Int).SetUint64(v))
+			}
+		}
+		if strconv.FormatUint(uint64(n), 16) != port {
+			hosts = append(hosts, big.NewInt(int64(n)))
+		}
+	}
+	return hosts
+}
+
+// WordyStrategy hex words as the last hextets, e.g. dead:beef is ::dead:beef
+func WordyStrategy(words []string) IPv6Strategy {
+	var hosts hostsStrategy
+	for _, word := range words {
+		if h, err := parseHextets(word); err == nil {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// parseHextets parse up to 4 hextets, e.g. dead:beef
+func parseHextets(s string) (*big.Int, error) {
+	hextets := strings.Split(s, ":")
+	if len(hextets) > 4 {
+		return nil, fmt.Errorf("%s has more than 4 hextets", s)
+	}
+	h := new(big.Int)
+	for _, hextet := range hextets {
+		v, err := strconv.ParseUint(hextet, 16, 16)
+		if err != nil {
+			return nil, err
+		}
+		h.Lsh(h, 16).Or(h, new(big.Int).SetUint64(v))
+	}
+	return h, nil
+}
+
+// EmbeddedIPv4Strategy embed ipv4 in the last 32 bits (::c0a8:101) and as decimal digits of hextets (::192:168:1:1)
+func EmbeddedIPv4Strategy(cs CIDRs) IPv6Strategy {
+	return embeddedIPv4Strategy(cs)
+}
+
+type embeddedIPv4Strategy CIDRs
+
+func (s embeddedIPv4Strategy) Hosts() HostIterator {
+	return &embeddedIPv4Hosts{ips: CIDRs(s).Iterator()}
+}
+
+type embeddedIPv4Hosts struct {
+	ips     *CIDRsIterator
+	pending *big.Int
+}
+
+func (it *embeddedIPv4Hosts) Next() (*big.Int, bool) {
+	if it.pending != nil {
+		h := it.pending
+		it.pending = nil
+		return h, true
+	}
+	for {
+		ip, ok := it.ips.Next()
+		if !ok {
+			return nil, false
+		}
+		if ip.Ver != IPV4 {
+			continue
+		}
+		words := make([]string, 4)
+		for i, b := range ip.IP.To4() {
+			words[i] = strconv.Itoa(int(b))
+		}
+		it.pending, _ = parseHextets(strings.Join(words, ":"))
+		return ip.BigInt(), true
+	}
+}
+
+// EUI64Strategy modified EUI-64 identifiers of SLAAC, every item is a mac (00:11:22:33:44:55) or an oui (00:11:22).
+// every nic of oui is generated lazily, that is 2^24 candidates
+func EUI64Strategy(macs []string) (IPv6Strategy, error) {
+	s := &eui64Strategy{}
+	for _, mac := range macs {
+		b, err := parseMAC(mac)
+		if err != nil {
+			return nil, err
+		}
+		s.macs = append(s.macs, b)
+	}
+	return s, nil
+}
+
+// parseMAC parse mac or oui separated by ":", "-" or "."
+func parseMAC(s string) ([]byte, error) {
+	if len(strings.FieldsFunc(s, isMACSeparator)) == 3 && len(s) == 8 {
+		sep := s[2:3]
+		hw, err := net.ParseMAC(s + sep + "00" + sep + "00" + sep + "00")
+		if err != nil {
+			return nil, err
+		}
+		return hw[:3], nil
+	}
+	hw, err := net.ParseMAC(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(hw) != 6 {
+		return nil, fmt.Errorf("%s is not a 48-bit mac", s)
+	}
+	return hw, nil
+}
+
+func isMACSeparator(r rune) bool {
+	return r == ':' || r == '-' || r == '.'
+}
+
+type eui64Strategy struct {
+	macs [][]byte
+}
+
+func (s *eui64Strategy) Hosts() HostIterator {
+	return &eui64Hosts{macs: s.macs}
+}
+
+// hostsIn EUI-64 identifiers are 64 bits, only exist in /64 or shorter prefix
+func (s *eui64Strategy) hostsIn(hostBits int) HostIterator {
+	if hostBits < 64 {
+		return &sliceHosts{}
+	}
+	return s.Hosts()
+}
+
+type eui64Hosts struct {
+	macs  [][]byte
+	index int
+	nic   uint32
+}
+
+func (it *eui64Hosts) Next() (*big.Int, bool) {
+	for it.index < len(it.macs) {
+		mac := it.macs[it.index]
+		if len(mac) == 6 {
+			it.index++
+			return eui64(mac), true
+		}
+		if it.nic >= 1<<24 {
+			it.index++
+			it.nic = 0
+			continue
+		}
+		h := eui64([]byte{mac[0], mac[1], mac[2], byte(it.nic >> 16), byte(it.nic >> 8), byte(it.nic)})
+		it.nic++
+		return h, true
+	}
+	return nil, false
+}
+
+// eui64 flip universal/local bit and insert ff:fe in the middle of mac
+func eui64(mac []byte) *big.Int {
+	return new(big.Int).SetBytes([]byte{mac[0] ^ 0x02, mac[1], mac[2], 0xff, 0xfe, mac[3], mac[4], mac[5]})
+}
+
+// CandidateIterator generate candidate ips of cidr from strategies in order.
+// host identifiers out of cidr are skipped, strategies may generate the same ip
+type CandidateIterator struct {
+	first      *big.Int
+	size       *big.Int
+	hostBits   int
+	ver        int
+	strategies []IPv6Strategy
+	index      int
+	cur        HostIterator
+}
+
+// Candidates return lazy candidate ips of cidr, DefaultIPv6Strategies is used if no strategy given
+func (c *CIDR) Candidates(strategies ...IPv6Strategy) *CandidateIterator {
+	if len(strategies) == 0 {
+		strategies = DefaultIPv6Strategies()
+	}
+	return &CandidateIterator{
+		first:      c.FirstIP().BigInt(),
+		size:       c.Count(),
+		hostBits:   c.Len()*8 - c.Mask,
+		ver:        c.Ver,
+		strategies: strategies,
+	}
+}
+
+func (it *CandidateIterator) Next() (*IP, bool) {
+	for {
+		if it.cur == nil {
+			if it.index >= len(it.strategies) {
+				return nil, false
+			}
+			if s, ok := it.strategies[it.index].(boundedStrategy); ok {
+				it.cur = s.hostsIn(it.hostBits)
+			} else {
+				it.cur = it.strategies[it.index].Hosts()
+			}
+			it.index++
+		}
+		h, ok := it.cur.Next()
+		if !ok {
+			it.cur = nil
+			continue
+		}
+		if h.Cmp(it.size) >= 0 {
+			continue
+		}
+		return newIPFromBig(new(big.Int).Or(it.first, h), it.ver), true
+	}
+}
+
+// Take return at most n candidates, all candidates if n <= 0, e.g. AddrsGenerator{IPs: it.Take(1000), Ports: ports}
+func (it *CandidateIterator) Take(n int) IPs {
+	var ips IPs
+	for n <= 0 || len(ips) < n {
+		ip, ok := it.Next()
+		if !ok {
+			break
+		}
+		ips = append(ips, ip)
+	}
+	return ips
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCIDR_Candidates(t *testing.T) {
+	c := ParseCIDR("2001:db8:1:2::/64")
+
+	ips := c.Candidates(LowByteStrategy(3)).Take(0)
+	assert.Equal(t, []string{"2001:db8:1:2::1", "2001:db8:1:2::2", "2001:db8:1:2::3"}, ips.Strings())
+
+	ips = c.Candidates(PortStrategy([]string{"80", "443", "1", "abc"})).Take(0)
+	assert.Equal(t, []string{"2001:db8:1:2::80", "2001:db8:1:2::50", "2001:db8:1:2::443", "2001:db8:1:2::1bb", "2001:db8:1:2::1"}, ips.Strings())
+
+	ips = c.Candidates(WordyStrategy([]string{"dead:beef", "cafe", "xyz"})).Take(0)
+	assert.Equal(t, []string{"2001:db8:1:2::dead:beef", "2001:db8:1:2::cafe"}, ips.Strings())
+
+	ips = c.Candidates(EmbeddedIPv4Strategy(ParseCIDRs([]string{"192.168.1.1", "10.0.0.0/31"}))).Take(0)
+	assert.Equal(t, []string{
+		"2001:db8:1:2::c0a8:101", "2001:db8:1:2:192:168:1:1",
+		"2001:db8:1:2::a00:0", "2001:db8:1:2:10::",
+		"2001:db8:1:2::a00:1", "2001:db8:1:2:10::1",
+	}, ips.Strings())
+
+	eui, err := EUI64Strategy([]string{"00:11:22:33:44:55", "52-54-00"})
+	assert.NoError(t, err)
+	it := c.Candidates(eui)
+	assert.Equal(t, []string{"2001:db8:1:2:211:22ff:fe33:4455", "2001:db8:1:2:5054:ff:fe00:0", "2001:db8:1:2:5054:ff:fe00:1"}, it.Take(3).Strings())
+	// eui-64 needs all 64 bits of host, oui is not walked for a longer prefix
+	oui, err := EUI64Strategy([]string{"52-54-00"})
+	assert.NoError(t, err)
+	assert.Len(t, ParseCIDR("2001:db8::/96").Candidates(oui).Take(0), 0)
+	assert.Len(t, ParseCIDR("2001:db8::/65").Candidates(oui).Take(0), 0)
+	_, err = EUI64Strategy([]string{"00:11"})
+	assert.Error(t, err)
+
+	// candidates out of cidr are skipped
+	ips = ParseCIDR("2001:db8::/120").Candidates(LowByteStrategy(0x1ff), WordyStrategy([]string{"dead:beef"})).Take(0)
+	assert.Len(t, ips, 0xff)
+
+	ips = c.Candidates().Take(10)
+	assert.Len(t, ips, 10)
+	gen := AddrsGenerator{IPs: ips, Ports: []string{"80", "443"}}
+	assert.Equal(t, int64(20), gen.Count().Int64())
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"github.com/chainreactors/utils/iputils"
+	"math/big"
 	"net"
 	"sort"
 	"strings"
@@ -27,7 +29,12 @@ func DifferenceCIDR(target, exclude *CIDR) CIDRs {
 }
 
 func NewCIDR(ip string, mask int) *CIDR {
-	c := &CIDR{IP: ParseIP(ip), Mask: mask}
+	return newCIDR(ParseIP(ip), mask)
+}
+
+// newCIDR mask 0 means single ip
+func newCIDR(ip *IP, mask int) *CIDR {
+	c := &CIDR{IP: ip, Mask: mask}
 	if c.IP == nil {
 		return nil
 	}
@@ -43,24 +50,35 @@ func NewCIDR(ip string, mask int) *CIDR {
 	return c
 }
 
+// ParseCIDRs parse ips, cidrs and ip ranges to CIDRs, auto skip wrong input
 func ParseCIDRs(ips []string) CIDRs {
 	var cs CIDRs
 	for _, ip := range ips {
-		c := ParseCIDR(ip)
-		if c != nil {
+		if rs, err := ParseIPRange(ip); err == nil {
+			cs = append(cs, rs.CIDRs()...)
+		} else if c := ParseCIDR(ip); c != nil {
 			cs = append(cs, c)
 		}
 	}
 	return cs
 }
 
+// NewCIDRFromNet version is decided by the length of mask, 16 bytes ipv4-mapped net is ipv6
 func NewCIDRFromNet(ip *net.IPNet) *CIDR {
 	mask, err := IPMaskToPrefixLength(ip.Mask)
 	if err != nil {
 		return nil
 	}
 
-	i := NewIP(ip.IP)
+	var i *IP
+	if len(ip.Mask) == net.IPv4len {
+		i = &IP{IP: ip.IP.To4(), Ver: IPV4}
+	} else {
+		i = &IP{IP: ip.IP.To16(), Ver: IPV6}
+	}
+	if i.IP == nil {
+		return nil
+	}
 	cidr := &CIDR{
 		IP:     i,
 		Mask:   mask,
@@ -71,18 +89,47 @@ func NewCIDRFromNet(ip *net.IPNet) *CIDR {
 
 }
 
-func ParseCIDR(target string) *CIDR {
+// newCIDRsFromNets convert nets of the same ip version to sorted CIDRs
+func newCIDRsFromNets(nets []*net.IPNet) CIDRs {
+	cs := make(CIDRs, 0, len(nets))
+	for _, n := range nets {
+		if c := NewCIDRFromNet(n); c != nil {
+			cs = append(cs, c)
+		}
+	}
+	sort.Sort(cs)
+	return cs
+}
+
+// ParseCIDR parse ip, cidr or ip range to CIDR, ip range will be parsed only if it is exactly one cidr.
+// hostname will be resolved by the optional resolver or DefaultResolver
+func ParseCIDR(target string, resolver ...Resolver) *CIDR {
 	// return ip, hosts
 	var ip string
 	var mask int
 	target = ParseHost(target)
+	if rs, err := ParseIPRange(target); err == nil {
+		if cs := rs.CIDRs(); len(cs) == 1 {
+			return cs[0]
+		}
+		return nil
+	} else if isRangeTooLarge(err) {
+		return nil
+	}
 	if strings.Contains(target, "/") {
 		ip, mask = SplitCIDR(target)
 	} else {
 		ip = target
 	}
 
-	return NewCIDR(ip, mask)
+	if mask == 0 && strings.HasSuffix(target, "/0") {
+		// NewCIDR treat mask 0 as single ip, explicit /0 means the whole address space
+		if i := ParseIP(ip, resolver...); i != nil {
+			return i.CIDR(0)
+		}
+		return nil
+	}
+	return newCIDR(ParseIP(ip, resolver...), mask)
 }
 
 type CIDR struct {
@@ -90,8 +137,8 @@ type CIDR struct {
 	Mask   int
 	maskIP *IP
 	curIP  *IP
-	cur    int
-	max    int
+	cur    *big.Int
+	max    *big.Int
 }
 
 func (c *CIDR) Len() int {
@@ -118,28 +165,42 @@ func (c *CIDR) LastIP() *IP {
 	return &IP{IP: ip, Ver: c.Ver}
 }
 
+// maxSplit limit count of subnets of Split, every subnet is allocated
+const maxSplit = 1 << 24
+
+// Split split cidr into subnets of mask
 func (c *CIDR) Split(mask int) (CIDRs, error) {
 	if c.Mask > mask {
 		return nil, fmt.Errorf("mask error, %d > %d", mask, c.Mask)
 	}
+	if mask > c.Len()*8 {
+		return nil, newParseError(c.String(), 0, ErrMaskOutOfRange)
+	}
+	if mask-c.Mask > 24 {
+		return nil, fmt.Errorf("too many subnets, %s split to /%d is more than %d", c.String(), mask, maxSplit)
+	}
 	block := 1 << uint(mask-c.Mask)
-	var cs CIDRs
+	step := new(big.Int).Lsh(bigOne, uint(c.Len()*8-mask))
+	cs := make(CIDRs, 0, block)
+	ip := c.FirstIP()
 	for i := 0; i < block; i++ {
-		if i == 0 {
-			cs = append(cs, c.FirstIP().CIDR(mask))
-		} else {
-			cs = append(cs, cs[i-1].LastIP().Next().CIDR(mask))
+		if i > 0 {
+			var err error
+			if ip, err = ip.Add(step); err != nil {
+				return nil, err
+			}
 		}
+		cs = append(cs, ip.CIDR(mask))
 	}
 	return cs, nil
 }
 
 func (c *CIDR) Net() *net.IPNet {
-	return &net.IPNet{c.IP.IP, net.IPMask(MaskToIP(c.Mask, c.Ver).IP)}
+	return &net.IPNet{IP: c.IP.IP, Mask: net.IPMask(MaskToIP(c.Mask, c.Ver).IP)}
 }
 
 func (c *CIDR) NetWithMask(mask int) *net.IPNet {
-	return &net.IPNet{c.IP.IP, net.IPMask(MaskToIP(mask, c.Ver).IP)}
+	return &net.IPNet{IP: c.IP.IP, Mask: net.IPMask(MaskToIP(mask, c.Ver).IP)}
 }
 
 func (c *CIDR) IPMask() net.IPMask {
@@ -150,12 +211,9 @@ func (c *CIDR) IPMask() net.IPMask {
 	}
 }
 
-func (c *CIDR) Count() int {
-	if c.Ver == 4 {
-		return 1 << uint(32-c.Mask)
-	} else {
-		return 1 << uint(128-c.Mask)
-	}
+// Count returns the number of addresses in the cidr, including network and broadcast address
+func (c *CIDR) Count() *big.Int {
+	return iputils.CountIPsInCIDR(true, true, c.Net())
 }
 
 func (c *CIDR) Compare(other *CIDR) int {
@@ -181,16 +239,25 @@ func (c *CIDR) Compare(other *CIDR) int {
 //	return first, final
 //}
 
+// Range push every ip of cidr to channel, the goroutine will be leaked if the consumer stops early, use RangeWithContext instead
 func (c *CIDR) Range() chan *IP {
-	ch := make(chan *IP)
-	go func() {
-		for i := 0; i < c.max; i++ {
-			ch <- c.Next()
-		}
-		close(ch)
-	}()
+	return c.RangeWithContext(context.Background())
+}
 
-	return ch
+// RangeWithContext like Range, but stop and close channel when ctx done
+func (c *CIDR) RangeWithContext(ctx context.Context) chan *IP {
+	return RangeIPIterator(ctx, c.Iterator())
+}
+
+// Iterator return a pull style iterator with its own state, without goroutine
+func (c *CIDR) Iterator() *CIDRIterator {
+	first := c.FirstIP()
+	return &CIDRIterator{
+		first: first,
+		curIP: first.Copy(),
+		cur:   new(big.Int),
+		max:   c.Count(),
+	}
 }
 
 func (c *CIDR) ContainsCIDR(cidr *CIDR) bool {
@@ -207,23 +274,23 @@ func (c *CIDR) ContainsIP(ip *IP) bool {
 }
 
 func (c *CIDR) Next() *IP {
-	if c.cur == 0 {
-		c.cur++
+	if c.cur.Sign() == 0 {
+		c.cur.Add(c.cur, bigOne)
 		return c.curIP.Copy()
 	}
 
-	if c.cur >= c.max {
+	if c.cur.Cmp(c.max) >= 0 {
 		c.Reset()
 		return c.Next()
 	}
-	c.cur++
+	c.cur.Add(c.cur, bigOne)
 	c.curIP.Next()
 	return c.curIP.Copy()
 }
 
 func (c *CIDR) Reset() {
 	c.max = c.Count()
-	c.cur = 0
+	c.cur = new(big.Int)
 	c.curIP = c.FirstIP()
 }
 
@@ -310,43 +377,40 @@ func (cs CIDRs) Coalesce() CIDRs {
 }
 
 func (cs CIDRs) Range() chan *IP {
-	ch := make(chan *IP)
-	go func() {
-		for _, c := range cs {
-			for ip := range c.Range() {
-				ch <- ip
-			}
-		}
-		close(ch)
-	}()
-	return ch
+	return cs.RangeWithContext(context.Background())
+}
+
+func (cs CIDRs) RangeWithContext(ctx context.Context) chan *IP {
+	return RangeIPIterator(ctx, cs.Iterator())
+}
+
+// Iterator return a pull style iterator, iterate cidrs one by one
+func (cs CIDRs) Iterator() *CIDRsIterator {
+	return &CIDRsIterator{cs: cs}
 }
 
+// SprayRange take one ip from every cidr in turn
 func (cs CIDRs) SprayRange() chan *IP {
-	ch := make(chan *IP)
-	length := cs.Len()
-	count := cs.Count()
-	go func() {
-		var i, vaild int
-		for {
-			if vaild == count {
-				break
-			}
-			if cs[i%length].cur < cs[i%length].max {
-				ch <- cs[i%length].Next()
-				vaild++
-			}
-			i++
-		}
-		close(ch)
-	}()
-	return ch
+	return cs.SprayRangeWithContext(context.Background())
+}
+
+func (cs CIDRs) SprayRangeWithContext(ctx context.Context) chan *IP {
+	return RangeIPIterator(ctx, cs.SprayIterator())
+}
+
+// SprayIterator return a pull style iterator, take one ip from every cidr in turn
+func (cs CIDRs) SprayIterator() *SprayIterator {
+	iters := make([]*CIDRIterator, len(cs))
+	for i, c := range cs {
+		iters[i] = c.Iterator()
+	}
+	return &SprayIterator{iters: iters}
 }
 
-func (cs CIDRs) Count() int {
-	var sum int
+func (cs CIDRs) Count() *big.Int {
+	sum := new(big.Int)
 	for _, c := range cs {
-		sum += c.Count()
+		sum.Add(sum, c.Count())
 	}
 	return sum
 }
@@ -2,17 +2,39 @@ package utils
 
 import (
 	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net"
 	"sort"
 	"testing"
 )
 
 func TestCIDR_Next(t *testing.T) {
 	c := NewCIDR("2001:0:53ab:0:0:0:0:0", 120)
-	for i := 0; i < c.max; i++ {
+	for i := int64(0); i < c.max.Int64(); i++ {
 		println(c.Next().String())
 	}
 }
 
+func TestCIDR_Count(t *testing.T) {
+	assert.Equal(t, "256", ParseCIDR("192.168.1.1/24").Count().String())
+	assert.Equal(t, "1", ParseCIDR("192.168.1.1").Count().String())
+	assert.Equal(t, "18446744073709551616", ParseCIDR("2001:db8::/64").Count().String())
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(1), 96).String(), ParseCIDR("2001:db8::/32").Count().String())
+
+	cs := CIDRs{ParseCIDR("10.0.0.0/8"), ParseCIDR("2001:db8::/48")}
+	expected := new(big.Int).Lsh(big.NewInt(1), 80)
+	expected.Add(expected, big.NewInt(1<<24))
+	assert.Equal(t, expected.String(), cs.Count().String())
+}
+
+func TestCIDR_NextHuge(t *testing.T) {
+	c := ParseCIDR("2001:db8::/33")
+	assert.Equal(t, "2001:db8::", c.Next().String())
+	assert.Equal(t, "2001:db8::1", c.Next().String())
+	assert.Equal(t, "2001:db8::2", c.Next().String())
+}
+
 func BenchmarkCIDR_Next100000(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		NewCIDR("2001:0:53ab:0:0:0:0:0", 120).Next()
@@ -86,3 +108,28 @@ func TestCIDRs_Coalesce(t *testing.T) {
 		println(i.String())
 	}
 }
+
+func TestParseCIDR_ZeroMask(t *testing.T) {
+	// explicit /0 is the whole address space, ip without mask is a single ip
+	assert.Equal(t, "0.0.0.0/0", ParseCIDR("0.0.0.0/0").String())
+	assert.Equal(t, "4294967296", ParseCIDR("10.1.2.3/0").Count().String())
+	assert.Equal(t, "::/0", ParseCIDR("::/0").String())
+	assert.Equal(t, "10.1.2.3/32", ParseCIDR("10.1.2.3").String())
+	assert.True(t, ParseCIDR("0.0.0.0/0").ContainsIP(ParseIP("8.8.8.8")))
+}
+
+func TestNewCIDRFromNet(t *testing.T) {
+	_, n, _ := net.ParseCIDR("10.0.0.0/8")
+	c := NewCIDRFromNet(n)
+	assert.Equal(t, IPV4, c.Ver)
+	assert.Equal(t, "10.0.0.0/8", c.String())
+
+	// version is decided by the mask, 16 bytes ipv4-mapped net is ipv6
+	_, n, _ = net.ParseCIDR("::ffff:10.0.0.0/120")
+	c = NewCIDRFromNet(n)
+	assert.Equal(t, IPV6, c.Ver)
+	assert.Equal(t, "::ffff:10.0.0.0/120", c.String())
+	assert.Equal(t, "256", c.Count().String())
+	_, n, _ = net.ParseCIDR("::ffff:0:0/96")
+	assert.Equal(t, "::ffff:0.0.0.0/96", NewCIDRFromNet(n).String())
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chainreactors/utils/iputils"
+	"math/big"
+	"net"
+)
+
+const (
+	CIDRTypeNetwork      = "network"
+	CIDRTypePointToPoint = "point-to-point"
+	CIDRTypeHost         = "host"
+)
+
+// CIDRInfo subnet calculator summary of cidr.
+// ipv4 /31 is point-to-point link (RFC 3021) and /32 is single host, both have no broadcast and every address is usable.
+// ipv6 has no broadcast, every address is usable, /127 is point-to-point link (RFC 6164)
+type CIDRInfo struct {
+	Address   *IP    `json:"address"`
+	CIDR      *CIDR  `json:"cidr"`
+	Version   int    `json:"version"`
+	Type      string `json:"type"`
+	Network   *IP    `json:"network"`
+	Broadcast *IP    `json:"broadcast,omitempty"`
+	Netmask   *IP    `json:"netmask"`
+	Wildcard  *IP    `json:"wildcard"`
+	FirstHost *IP    `json:"first_host"`
+	LastHost  *IP    `json:"last_host"`
+	// Total count of all addresses, including network and broadcast
+	Total *big.Int `json:"total"`
+	// Usable count of host addresses
+	Usable *big.Int `json:"usable"`
+	// Classful A, B, C, D(multicast) or E(reserved), empty for ipv6
+	Classful string `json:"classful,omitempty"`
+}
+
+// Info return subnet calculator summary of cidr
+func (c *CIDR) Info() *CIDRInfo {
+	bits := c.Len() * 8
+	network := c.FirstIP()
+	last := c.LastIP()
+	netmask := MaskToIP(c.Mask, c.Ver)
+	wildcard := make(net.IP, c.Len())
+	for i := range wildcard {
+		wildcard[i] = ^netmask.IP[i]
+	}
+
+	info := &CIDRInfo{
+		Address:   c.IP.Copy(),
+		CIDR:      network.CIDR(c.Mask),
+		Version:   c.Ver,
+		Type:      CIDRTypeNetwork,
+		Network:   network,
+		Netmask:   netmask,
+		Wildcard:  &IP{IP: wildcard, Ver: c.Ver},
+		FirstHost: network,
+		LastHost:  last,
+		Total:     c.Count(),
+		Usable:    c.Count(),
+	}
+	switch c.Mask {
+	case bits:
+		info.Type = CIDRTypeHost
+	case bits - 1:
+		info.Type = CIDRTypePointToPoint
+	}
+
+	if c.Ver == IPV4 {
+		info.Classful = classfulClass(network)
+		if info.Type == CIDRTypeNetwork {
+			info.Broadcast = last
+			info.Usable = iputils.CountIPsInCIDR(false, false, c.Net())
+			// network and broadcast are never the first or the last address, no overflow
+			info.FirstHost, _ = network.Succ()
+			info.LastHost, _ = last.Prev()
+		}
+	}
+	return info
+}
+
+// String multi-line text like ipcalc
+func (info *CIDRInfo) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Address:   %s\n", info.Address.String())
+	fmt.Fprintf(&buf, "Network:   %s\n", info.CIDR.String())
+	fmt.Fprintf(&buf, "Netmask:   %s = %d\n", info.Netmask.String(), info.CIDR.Mask)
+	fmt.Fprintf(&buf, "Wildcard:  %s\n", info.Wildcard.String())
+	if info.Broadcast != nil {
+		fmt.Fprintf(&buf, "Broadcast: %s\n", info.Broadcast.String())
+	}
+	fmt.Fprintf(&buf, "HostMin:   %s\n", info.FirstHost.String())
+	fmt.Fprintf(&buf, "HostMax:   %s\n", info.LastHost.String())
+	fmt.Fprintf(&buf, "Total:     %s\n", info.Total.String())
+	fmt.Fprintf(&buf, "Hosts/Net: %s", info.Usable.String())
+	switch info.Type {
+	case CIDRTypePointToPoint:
+		buf.WriteString(", point-to-point")
+	case CIDRTypeHost:
+		buf.WriteString(", host route")
+	}
+	if info.Classful != "" {
+		fmt.Fprintf(&buf, ", Class %s", info.Classful)
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// classfulClass classful network class of ipv4 by leading bits of first octet
+func classfulClass(ip *IP) string {
+	b := ip.IP.To4()[0]
+	switch {
+	case b < 128:
+		return "A"
+	case b < 192:
+		return "B"
+	case b < 224:
+		return "C"
+	case b < 240:
+		return "D"
+	default:
+		return "E"
+	}
+}
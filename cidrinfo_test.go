@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCIDR_Info(t *testing.T) {
+	info := ParseCIDR("192.168.1.10/24").Info()
+	assert.Equal(t, "192.168.1.10", info.Address.String())
+	assert.Equal(t, "192.168.1.0/24", info.CIDR.String())
+	assert.Equal(t, "192.168.1.0", info.Network.String())
+	assert.Equal(t, "192.168.1.255", info.Broadcast.String())
+	assert.Equal(t, "255.255.255.0", info.Netmask.String())
+	assert.Equal(t, "0.0.0.255", info.Wildcard.String())
+	assert.Equal(t, "192.168.1.1", info.FirstHost.String())
+	assert.Equal(t, "192.168.1.254", info.LastHost.String())
+	assert.Equal(t, int64(256), info.Total.Int64())
+	assert.Equal(t, int64(254), info.Usable.Int64())
+	assert.Equal(t, "C", info.Classful)
+	assert.Equal(t, CIDRTypeNetwork, info.Type)
+
+	info = ParseCIDR("10.0.0.0/31").Info()
+	assert.Equal(t, CIDRTypePointToPoint, info.Type)
+	assert.Nil(t, info.Broadcast)
+	assert.Equal(t, "10.0.0.0", info.FirstHost.String())
+	assert.Equal(t, "10.0.0.1", info.LastHost.String())
+	assert.Equal(t, int64(2), info.Usable.Int64())
+	assert.Equal(t, "A", info.Classful)
+
+	info = ParseCIDR("172.16.0.1/32").Info()
+	assert.Equal(t, CIDRTypeHost, info.Type)
+	assert.Equal(t, int64(1), info.Usable.Int64())
+	assert.Equal(t, "B", info.Classful)
+	assert.Equal(t, "0.0.0.0", info.Wildcard.String())
+
+	assert.Equal(t, "D", ParseCIDR("224.0.0.0/4").Info().Classful)
+	assert.Equal(t, "E", ParseCIDR("240.0.0.0/4").Info().Classful)
+
+	info = ParseCIDR("2001:db8::1/64").Info()
+	assert.Nil(t, info.Broadcast)
+	assert.Equal(t, "", info.Classful)
+	assert.Equal(t, "2001:db8::", info.FirstHost.String())
+	assert.Equal(t, "2001:db8::ffff:ffff:ffff:ffff", info.LastHost.String())
+	assert.Equal(t, "ffff:ffff:ffff:ffff::", info.Netmask.String())
+	assert.Equal(t, "::ffff:ffff:ffff:ffff", info.Wildcard.String())
+	assert.Equal(t, "18446744073709551616", info.Usable.String())
+	assert.Equal(t, CIDRTypePointToPoint, ParseCIDR("2001:db8::/127").Info().Type)
+	assert.Equal(t, CIDRTypeHost, ParseCIDR("2001:db8::1/128").Info().Type)
+}
+
+func TestCIDRInfo_Render(t *testing.T) {
+	info := ParseCIDR("192.168.1.10/30").Info()
+	assert.Equal(t, "Address:   192.168.1.10\n"+
+		"Network:   192.168.1.8/30\n"+
+		"Netmask:   255.255.255.252 = 30\n"+
+		"Wildcard:  0.0.0.3\n"+
+		"Broadcast: 192.168.1.11\n"+
+		"HostMin:   192.168.1.9\n"+
+		"HostMax:   192.168.1.10\n"+
+		"Total:     4\n"+
+		"Hosts/Net: 2, Class C\n", info.String())
+
+	b, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"address":"192.168.1.10","cidr":"192.168.1.8/30","version":4,"type":"network",
+		"network":"192.168.1.8","broadcast":"192.168.1.11","netmask":"255.255.255.252","wildcard":"0.0.0.3",
+		"first_host":"192.168.1.9","last_host":"192.168.1.10","total":4,"usable":2,"classful":"C"}`, string(b))
+
+	b, _ = json.Marshal(ParseCIDR("10.0.0.1/32").Info())
+	assert.NotContains(t, string(b), "broadcast")
+}
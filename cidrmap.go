@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"net"
+)
+
+type cidrMapNode struct {
+	children [2]*cidrMapNode
+	entry    *CIDRMapEntry
+}
+
+// CIDRMapEntry prefix and the value attached to it
+type CIDRMapEntry struct {
+	CIDR  *CIDR
+	Value interface{}
+}
+
+// CIDRMap map cidr to value, support longest prefix match, backed by binary radix trie.
+// CIDRMap is not safe for concurrent modification
+type CIDRMap struct {
+	v4   *cidrMapNode
+	v6   *cidrMapNode
+	size int
+}
+
+func NewCIDRMap() *CIDRMap {
+	return &CIDRMap{v4: &cidrMapNode{}, v6: &cidrMapNode{}}
+}
+
+func (m *CIDRMap) root(ver int) *cidrMapNode {
+	if ver == IPV4 {
+		return m.v4
+	}
+	return m.v6
+}
+
+// find return the node of prefix, create missing nodes if create is true
+func (m *CIDRMap) find(ip net.IP, ver, mask int, create bool) *cidrMapNode {
+	n := m.root(ver)
+	for depth := 0; depth < mask; depth++ {
+		b := ipBit(ip, depth)
+		if n.children[b] == nil {
+			if !create {
+				return nil
+			}
+			n.children[b] = &cidrMapNode{}
+		}
+		n = n.children[b]
+	}
+	return n
+}
+
+func (m *CIDRMap) Len() int {
+	return m.size
+}
+
+// Insert set value of cidr, host bits of cidr will be ignored, old value will be replaced
+func (m *CIDRMap) Insert(c *CIDR, value interface{}) {
+	n := m.find(ipBytes(c.IP), c.Ver, c.Mask, true)
+	if n.entry == nil {
+		m.size++
+	}
+	n.entry = &CIDRMapEntry{CIDR: c.FirstIP().CIDR(c.Mask), Value: value}
+}
+
+// Get return value of exactly the cidr
+func (m *CIDRMap) Get(c *CIDR) (interface{}, bool) {
+	n := m.find(ipBytes(c.IP), c.Ver, c.Mask, false)
+	if n == nil || n.entry == nil {
+		return nil, false
+	}
+	return n.entry.Value, true
+}
+
+// Delete remove exactly the cidr, return false if not found
+func (m *CIDRMap) Delete(c *CIDR) bool {
+	ip := ipBytes(c.IP)
+	path := []*cidrMapNode{m.root(c.Ver)}
+	for depth := 0; depth < c.Mask; depth++ {
+		next := path[len(path)-1].children[ipBit(ip, depth)]
+		if next == nil {
+			return false
+		}
+		path = append(path, next)
+	}
+	if path[len(path)-1].entry == nil {
+		return false
+	}
+	path[len(path)-1].entry = nil
+	m.size--
+
+	// prune empty nodes
+	for depth := c.Mask; depth > 0; depth-- {
+		n := path[depth]
+		if n.entry != nil || n.children[0] != nil || n.children[1] != nil {
+			break
+		}
+		path[depth-1].children[ipBit(ip, depth-1)] = nil
+	}
+	return true
+}
+
+// covering return all entries covering prefix of ip/mask, shortest prefix first
+func (m *CIDRMap) covering(ip net.IP, ver, mask int) []*CIDRMapEntry {
+	var entries []*CIDRMapEntry
+	n := m.root(ver)
+	for depth := 0; n != nil; depth++ {
+		if n.entry != nil {
+			entries = append(entries, n.entry)
+		}
+		if depth == mask {
+			break
+		}
+		n = n.children[ipBit(ip, depth)]
+	}
+	return entries
+}
+
+// Lookup return the most specific prefix containing ip and its value
+func (m *CIDRMap) Lookup(ip *IP) (*CIDRMapEntry, bool) {
+	entries := m.covering(ipBytes(ip), ip.Ver, ip.Len()*8)
+	if len(entries) == 0 {
+		return nil, false
+	}
+	return entries[len(entries)-1], true
+}
+
+// LookupCIDR return the most specific prefix containing the whole cidr, cidr itself included
+func (m *CIDRMap) LookupCIDR(c *CIDR) (*CIDRMapEntry, bool) {
+	entries := m.covering(ipBytes(c.IP), c.Ver, c.Mask)
+	if len(entries) == 0 {
+		return nil, false
+	}
+	return entries[len(entries)-1], true
+}
+
+// Covering return all prefixes containing ip, shortest prefix first
+func (m *CIDRMap) Covering(ip *IP) []*CIDRMapEntry {
+	return m.covering(ipBytes(ip), ip.Ver, ip.Len()*8)
+}
+
+// Walk visit entries in address order, ipv4 first, shorter prefix before its sub prefixes.
+// stop walking if fn returns false
+func (m *CIDRMap) Walk(fn func(c *CIDR, value interface{}) bool) {
+	if walkCIDRMap(m.v4, fn) {
+		walkCIDRMap(m.v6, fn)
+	}
+}
+
+func walkCIDRMap(n *cidrMapNode, fn func(c *CIDR, value interface{}) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.entry != nil && !fn(n.entry.CIDR, n.entry.Value) {
+		return false
+	}
+	return walkCIDRMap(n.children[0], fn) && walkCIDRMap(n.children[1], fn)
+}
+
+// Entries return all entries in Walk order
+func (m *CIDRMap) Entries() []*CIDRMapEntry {
+	entries := make([]*CIDRMapEntry, 0, m.size)
+	m.Walk(func(c *CIDR, value interface{}) bool {
+		entries = append(entries, &CIDRMapEntry{CIDR: c, Value: value})
+		return true
+	})
+	return entries
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCIDRMap(t *testing.T) {
+	m := NewCIDRMap()
+	m.Insert(ParseCIDR("10.0.0.0/8"), "corp")
+	m.Insert(ParseCIDR("10.1.2.3/16"), "site-a")
+	m.Insert(ParseCIDR("10.1.2.0/24"), "office")
+	m.Insert(ParseCIDR("2001:db8::/32"), "v6")
+	m.Insert(ParseCIDR("10.0.0.0/8"), "corp-net")
+	assert.Equal(t, 4, m.Len())
+
+	e, ok := m.Lookup(ParseIP("10.1.2.100"))
+	assert.True(t, ok)
+	assert.Equal(t, "10.1.2.0/24", e.CIDR.String())
+	assert.Equal(t, "office", e.Value)
+
+	e, _ = m.Lookup(ParseIP("10.1.3.1"))
+	assert.Equal(t, "site-a", e.Value)
+	assert.Equal(t, "10.1.0.0/16", e.CIDR.String())
+
+	_, ok = m.Lookup(ParseIP("192.168.1.1"))
+	assert.False(t, ok)
+
+	e, _ = m.LookupCIDR(ParseCIDR("10.1.0.0/20"))
+	assert.Equal(t, "site-a", e.Value)
+
+	var covering []interface{}
+	for _, e := range m.Covering(ParseIP("10.1.2.1")) {
+		covering = append(covering, e.Value)
+	}
+	assert.Equal(t, []interface{}{"corp-net", "site-a", "office"}, covering)
+
+	v, ok := m.Get(ParseCIDR("2001:db8::/32"))
+	assert.True(t, ok)
+	assert.Equal(t, "v6", v)
+
+	assert.True(t, m.Delete(ParseCIDR("10.1.0.0/16")))
+	assert.False(t, m.Delete(ParseCIDR("10.1.0.0/16")))
+	e, _ = m.Lookup(ParseIP("10.1.3.1"))
+	assert.Equal(t, "corp-net", e.Value)
+
+	var walked []string
+	m.Walk(func(c *CIDR, value interface{}) bool {
+		walked = append(walked, c.String())
+		return true
+	})
+	assert.Equal(t, []string{"10.0.0.0/8", "10.1.2.0/24", "2001:db8::/32"}, walked)
+	assert.Len(t, m.Entries(), 3)
+}
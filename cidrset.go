@@ -0,0 +1,32 @@
+package utils
+
+// set algebra of CIDRs is computed by IPSet, iputils.RemoveCIDRs treats ipv4-mapped ipv6 prefix as ipv4
+// and refuses to mix them with other ipv6 prefixes, e.g. ::ffff:0:0/96
+
+// Union return the minimal and sorted cidrs covering both cs and other, ipv4 first
+func (cs CIDRs) Union(other CIDRs) CIDRs {
+	s := NewIPSet(cs)
+	for _, c := range other {
+		s.AddCIDR(c)
+	}
+	return s.CIDRs()
+}
+
+// Subtract return the minimal and sorted cidrs of cs excluding other, ipv4 first
+func (cs CIDRs) Subtract(other CIDRs) CIDRs {
+	s := NewIPSet(cs)
+	for _, c := range other {
+		s.RemoveCIDR(c)
+	}
+	return s.CIDRs()
+}
+
+// Intersect return the minimal and sorted cidrs in both cs and other, ipv4 first
+func (cs CIDRs) Intersect(other CIDRs) CIDRs {
+	return cs.Subtract(cs.Subtract(other))
+}
+
+// SymmetricDifference return the minimal and sorted cidrs in either cs or other but not both, ipv4 first
+func (cs CIDRs) SymmetricDifference(other CIDRs) CIDRs {
+	return cs.Subtract(other).Union(other.Subtract(cs))
+}
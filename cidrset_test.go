@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCIDRs_SetAlgebra(t *testing.T) {
+	a := ParseCIDRs([]string{"10.0.0.0/24", "10.0.1.0/24", "192.168.0.0/16", "2001:db8::/32"})
+	b := ParseCIDRs([]string{"10.0.0.128/25", "10.0.2.0/24", "192.168.1.0/24", "2001:db8:1::/48"})
+
+	assert.Equal(t, []string{"10.0.0.0/23", "10.0.2.0/24", "192.168.0.0/16", "2001:db8::/32"}, a.Union(b).Strings())
+	assert.Equal(t, []string{"10.0.0.128/25", "192.168.1.0/24", "2001:db8:1::/48"}, a.Intersect(b).Strings())
+	assert.Equal(t, []string{"10.0.0.0/25", "10.0.1.0/24", "192.168.0.0/24", "192.168.2.0/23", "192.168.4.0/22", "192.168.8.0/21", "192.168.16.0/20", "192.168.32.0/19", "192.168.64.0/18", "192.168.128.0/17",
+		"2001:db8::/48", "2001:db8:2::/47", "2001:db8:4::/46", "2001:db8:8::/45", "2001:db8:10::/44", "2001:db8:20::/43", "2001:db8:40::/42", "2001:db8:80::/41", "2001:db8:100::/40", "2001:db8:200::/39", "2001:db8:400::/38", "2001:db8:800::/37", "2001:db8:1000::/36", "2001:db8:2000::/35", "2001:db8:4000::/34", "2001:db8:8000::/33"},
+		a.Subtract(b).Strings())
+	assert.Equal(t, []string{"10.0.0.0/25", "10.0.1.0/24", "10.0.2.0/24"}, ParseCIDRs([]string{"10.0.0.0/23"}).SymmetricDifference(ParseCIDRs([]string{"10.0.0.128/25", "10.0.2.0/24"})).Strings())
+	assert.Equal(t, []string{"::/1"}, ParseCIDRs([]string{"::/0"}).Subtract(ParseCIDRs([]string{"8000::/1"})).Strings())
+	assert.Empty(t, a.Subtract(a))
+
+	// ipv4-mapped ipv6 prefix is kept as ipv6
+	mapped := ParseCIDRs([]string{"::/0"}).Subtract(ParseCIDRs([]string{"::ffff:0:0/96"}))
+	assert.False(t, mapped.ContainsCIDR(ParseCIDR("::ffff:0:0/96")))
+	assert.True(t, mapped.ContainsCIDR(ParseCIDR("2001:db8::/32")))
+	assert.Equal(t, []string{"::ffff:0.0.0.0/96"}, ParseCIDRs([]string{"::/64"}).Intersect(ParseCIDRs([]string{"::ffff:0:0/96"})).Strings())
+	assert.Equal(t, []string{"10.0.0.0/24", "::ffff:10.0.0.0/120"}, CIDRs{ParseCIDR("::ffff:10.0.0.0/120"), ParseCIDR("10.0.0.0/24")}.Union(nil).Strings())
+}
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidIP      = errors.New("invalid ip")
+	ErrInvalidAddr    = errors.New("invalid address")
+	ErrInvalidRange   = errors.New("ip range is not a single cidr")
+	ErrMaskOutOfRange = errors.New("mask out of range")
+	ErrPortRange      = errors.New("port out of range")
+	ErrResolve        = errors.New("unable to resolve host")
+	ErrIPOverflow     = errors.New("ip out of address space")
+	ErrIPVersion      = errors.New("ip version mismatch")
+	ErrNoFreeSpace    = errors.New("no free space")
+	ErrRangeTooLarge  = errors.New("ip range too large")
+)
+
+// ParseError error of parsing target, Err is one of ErrInvalidIP, ErrMaskOutOfRange, ErrPortRange, ErrResolve...
+type ParseError struct {
+	Input string
+	// File file of input, empty if not from file
+	File string
+	// Line line number of batch input, start from 1, 0 means not from batch
+	Line int
+	// Pos byte offset of the offending part in Input
+	Pos int
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	s := fmt.Sprintf("%s %q", e.Err.Error(), e.Input)
+	if e.Pos > 0 {
+		s += " at " + strconv.Itoa(e.Pos)
+	}
+	if e.File != "" {
+		s = e.File + ":" + strconv.Itoa(e.Line) + ": " + s
+	} else if e.Line > 0 {
+		s = "line " + strconv.Itoa(e.Line) + ": " + s
+	}
+	return s
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(input string, pos int, err error) *ParseError {
+	return &ParseError{Input: input, Pos: pos, Err: err}
+}
+
+// ParseErrors per-line errors of batch parser
+type ParseErrors []*ParseError
+
+func (es ParseErrors) Error() string {
+	ss := make([]string, len(es))
+	for i, e := range es {
+		ss[i] = e.Error()
+	}
+	return strings.Join(ss, "\n")
+}
+
+// err return nil if there is no error, avoid non-nil interface holding nil slice
+func (es ParseErrors) err() error {
+	if len(es) == 0 {
+		return nil
+	}
+	return es
+}
+
+// looksLikeIP input only contains ip literal characters, it should not be resolved as hostname
+func looksLikeIP(s string) bool {
+	if strings.Contains(s, ":") {
+		return true
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != '.' && c != '-' && c != '*' && c != '/' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ParseIPE like ParseIP, but return ErrInvalidIP or ErrResolve
+func ParseIPE(s string, resolver ...Resolver) (*IP, error) {
+	s = strings.TrimSpace(s)
+	if ip := parseIPLiteral(s); ip != nil {
+		return ip, nil
+	}
+	if looksLikeIP(s) {
+		return nil, newParseError(s, 0, ErrInvalidIP)
+	}
+	ip, err := ParseHostToIP(s, resolver...)
+	if err != nil {
+		return nil, newParseError(s, 0, ErrResolve)
+	}
+	return ip, nil
+}
+
+// ParseCIDRE like ParseCIDR, but return ErrInvalidIP, ErrInvalidRange, ErrMaskOutOfRange or ErrResolve
+func ParseCIDRE(s string, resolver ...Resolver) (*CIDR, error) {
+	target := ParseHost(s)
+	if rs, err := ParseIPRange(target); err == nil {
+		if cs := rs.CIDRs(); len(cs) == 1 {
+			return cs[0], nil
+		}
+		return nil, newParseError(s, 0, ErrInvalidRange)
+	} else if isRangeTooLarge(err) {
+		return nil, newParseError(s, 0, ErrRangeTooLarge)
+	}
+
+	host, mask := target, -1
+	if i := strings.LastIndex(target, "/"); i != -1 {
+		host = target[:i]
+		m, err := strconv.Atoi(target[i+1:])
+		if err != nil || m < 0 {
+			return nil, newParseError(s, strings.LastIndex(s, "/")+1, ErrMaskOutOfRange)
+		}
+		mask = m
+	}
+	ip, err := ParseIPE(host, resolver...)
+	if err != nil {
+		e := err.(*ParseError)
+		e.Input, e.Pos = s, strings.Index(s, host)
+		return nil, e
+	}
+	if mask == -1 {
+		return ip.CIDR(ip.Len() * 8), nil
+	}
+	if mask > ip.Len()*8 {
+		return nil, newParseError(s, strings.LastIndex(s, "/")+1, ErrMaskOutOfRange)
+	}
+	return ip.CIDR(mask), nil
+}
+
+// NewCIDRE like NewCIDR, mask 0 means single ip
+func NewCIDRE(ip string, mask int) (*CIDR, error) {
+	i, err := ParseIPE(ip)
+	if err != nil {
+		return nil, err
+	}
+	if mask < 0 || mask > i.Len()*8 {
+		return nil, newParseError(ip+"/"+strconv.Itoa(mask), len(ip)+1, ErrMaskOutOfRange)
+	}
+	return newCIDR(i, mask), nil
+}
+
+// NewAddrE like NewAddr, but return ErrInvalidAddr, ErrInvalidIP, ErrResolve or ErrPortRange
+func NewAddrE(s string, resolver ...Resolver) (*Addr, error) {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(s))
+	if err != nil {
+		return nil, newParseError(s, 0, ErrInvalidAddr)
+	}
+	ip, err := ParseIPE(host, resolver...)
+	if err != nil {
+		e := err.(*ParseError)
+		e.Input, e.Pos = s, strings.Index(s, host)
+		return nil, e
+	}
+	if _, err := parsePortNumber(port); err != nil {
+		return nil, newParseError(s, strings.LastIndex(s, ":")+1, ErrPortRange)
+	}
+	return &Addr{ip, port}, nil
+}
+
+func MustParseIP(s string) *IP {
+	ip, err := ParseIPE(s)
+	if err != nil {
+		panic(err)
+	}
+	return ip
+}
+
+func MustParseCIDR(s string) *CIDR {
+	c, err := ParseCIDRE(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func MustNewAddr(s string) *Addr {
+	addr, err := NewAddrE(s)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
+// ParseIPsE parse ips line by line, blank and comment lines are skipped, errors of all lines are collected as ParseErrors
+func ParseIPsE(lines []string, resolver ...Resolver) (IPs, error) {
+	var ips IPs
+	var errs ParseErrors
+	forEachLine(lines, func(line string, lineno int) {
+		if rs, err := ParseIPRange(line); err == nil {
+			is, err := expandIPRanges(line, rs)
+			if err != nil {
+				errs = append(errs, withLine(err, lineno))
+				return
+			}
+			ips = append(ips, is...)
+			return
+		} else if isRangeTooLarge(err) {
+			errs = append(errs, withLine(err, lineno))
+			return
+		}
+		ip, err := ParseIPE(line, resolver...)
+		if err != nil {
+			errs = append(errs, withLine(err, lineno))
+			return
+		}
+		ips = append(ips, ip)
+	})
+	return ips, errs.err()
+}
+
+// ParseCIDRsE parse cidrs line by line, ip ranges are expanded to cidrs, errors of all lines are collected as ParseErrors
+func ParseCIDRsE(lines []string, resolver ...Resolver) (CIDRs, error) {
+	var cs CIDRs
+	var errs ParseErrors
+	forEachLine(lines, func(line string, lineno int) {
+		if rs, err := ParseIPRange(line); err == nil {
+			cs = append(cs, rs.CIDRs()...)
+			return
+		}
+		c, err := ParseCIDRE(line, resolver...)
+		if err != nil {
+			errs = append(errs, withLine(err, lineno))
+			return
+		}
+		cs = append(cs, c)
+	})
+	return cs, errs.err()
+}
+
+// ParseAddrsE parse ip:port or ip with default port line by line, errors of all lines are collected as ParseErrors
+func ParseAddrsE(lines []string, port string, resolver ...Resolver) (Addrs, error) {
+	var addrs Addrs
+	var errs ParseErrors
+	forEachLine(lines, func(line string, lineno int) {
+		s := line
+		if _, _, err := net.SplitHostPort(line); err != nil {
+			s = net.JoinHostPort(strings.Trim(line, "[]"), port)
+		}
+		addr, err := NewAddrE(s, resolver...)
+		if err != nil {
+			e := withLine(err, lineno)
+			if s != line {
+				e.Input, e.Pos = line, 0
+			}
+			errs = append(errs, e)
+			return
+		}
+		addrs = append(addrs, addr)
+	})
+	return addrs, errs.err()
+}
+
+func forEachLine(lines []string, fn func(line string, lineno int)) {
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(line, i+1)
+	}
+}
+
+func withLine(err error, lineno int) *ParseError {
+	e, ok := err.(*ParseError)
+	if !ok {
+		e = &ParseError{Err: err}
+	}
+	e.Line = lineno
+	return e
+}
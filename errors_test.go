@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseE(t *testing.T) {
+	ip, err := ParseIPE("10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", ip.String())
+
+	_, err = ParseIPE("10.0.0.256")
+	assert.True(t, errors.Is(err, ErrInvalidIP))
+	assert.EqualError(t, err, `invalid ip "10.0.0.256"`)
+	_, err = ParseIPE("no.such.host", NoDNS)
+	assert.True(t, errors.Is(err, ErrResolve))
+
+	c, err := ParseCIDRE("10.0.0.1/24")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1/24", c.String())
+	c, err = ParseCIDRE("10.0.0.0-10.0.0.255")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/24", c.String())
+	c, err = ParseCIDRE("0.0.0.0/0")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.Mask)
+
+	_, err = ParseCIDRE("10.0.0.0/33")
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, ErrMaskOutOfRange, pe.Err)
+	assert.Equal(t, 9, pe.Pos)
+	_, err = ParseCIDRE("2001:db8::/129")
+	assert.True(t, errors.Is(err, ErrMaskOutOfRange))
+	_, err = ParseCIDRE("10.0.0.1-10.0.0.5")
+	assert.True(t, errors.Is(err, ErrInvalidRange))
+	_, err = ParseCIDRE("10.0.0.300/24")
+	assert.True(t, errors.Is(err, ErrInvalidIP))
+
+	_, err = NewCIDRE("10.0.0.0", 40)
+	assert.True(t, errors.Is(err, ErrMaskOutOfRange))
+	c, err = NewCIDRE("10.0.0.1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, c.Mask)
+
+	addr, err := NewAddrE("[::1]:8080")
+	assert.NoError(t, err)
+	assert.Equal(t, "[::1]:8080", addr.String())
+	_, err = NewAddrE("10.0.0.1:70000")
+	assert.True(t, errors.Is(err, ErrPortRange))
+	assert.EqualError(t, err, `port out of range "10.0.0.1:70000" at 9`)
+	_, err = NewAddrE("10.0.0.1")
+	assert.True(t, errors.Is(err, ErrInvalidAddr))
+
+	// version of ipv4-mapped address is the same as ParseIP
+	ip, err = ParseIPE("::ffff:1.2.3.4")
+	assert.NoError(t, err)
+	assert.Equal(t, ParseIP("::ffff:1.2.3.4").Ver, ip.Ver)
+	assert.Equal(t, "::ffff:1.2.3.4", ip.String())
+
+	assert.Panics(t, func() { MustParseIP("10.0.0.999") })
+	assert.Equal(t, "10.0.0.0/8", MustParseCIDR("10.0.0.0/8").String())
+	assert.Equal(t, "1.1.1.1:53", MustNewAddr("1.1.1.1:53").String())
+}
+
+func TestParsePortsE(t *testing.T) {
+	preset := NewPortPreset([]*PortConfig{{Name: "web", Ports: []string{"80", "443"}, Tags: []string{"http"}}})
+	ports, err := preset.ParsePortStringE("web, 22, 1-3, -443")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"80", "22", "1", "2", "3"}, ports)
+
+	_, err = preset.ParsePortStringE("80,abc-def")
+	assert.True(t, errors.Is(err, ErrPortRange))
+	assert.Equal(t, 3, err.(*ParseError).Pos)
+	_, err = preset.ParsePortStringE("80,ssh")
+	assert.True(t, errors.Is(err, ErrPortRange))
+	_, err = preset.ParsePortStringE("100-1")
+	assert.Error(t, err)
+
+	ports, err = ParsePortsE("65530-")
+	assert.NoError(t, err)
+	assert.Len(t, ports, 6)
+
+	// garbage range is skipped instead of expanding from port 0
+	assert.Equal(t, []string{"80"}, expandPorts([]string{"80", "abc-3"}))
+}
+
+func TestParseBatchE(t *testing.T) {
+	cs, err := ParseCIDRsE([]string{"# targets", "10.0.0.0/24", "", "10.0.1.0/33", "192.168.0.1-192.168.0.2", "bad..ip"}, NoDNS)
+	assert.Equal(t, []string{"10.0.0.0/24", "192.168.0.1/32", "192.168.0.2/32"}, cs.Strings())
+	errs, ok := err.(ParseErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 4, errs[0].Line)
+	assert.True(t, errors.Is(errs[0], ErrMaskOutOfRange))
+	assert.Equal(t, 6, errs[1].Line)
+	assert.Equal(t, "line 6: unable to resolve host \"bad..ip\"", errs[1].Error())
+
+	ips, err := ParseIPsE([]string{"10.0.0.1", "10.0.0.5-6"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.5", "10.0.0.6"}, ips.Strings())
+
+	addrs, err := ParseAddrsE([]string{"10.0.0.1", "::1", "10.0.0.2:22", "10.0.0.3:99999"}, "80")
+	assert.Len(t, addrs, 3)
+	assert.Equal(t, "[::1]:80", addrs[1].String())
+	assert.Len(t, err.(ParseErrors), 1)
+}
@@ -26,6 +26,20 @@ func LoadFileToSlice(filename string) ([]string, error) {
 	return ss, nil
 }
 
+// LoadFileLines load every line of file, blank lines are kept, so index+1 is the line number
+func LoadFileLines(filename string) ([]string, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return lines, nil
+}
+
 func LoadFileWithCache(filename string) ([]string, error) {
 	if dict, ok := DictCache[filename]; ok {
 		return dict, nil
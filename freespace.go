@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"github.com/chainreactors/utils/iputils"
+	"net"
+)
+
+// FreeSpace return the minimal sorted cidrs of c not covered by used, used of other ip version is ignored
+func (c *CIDR) FreeSpace(used CIDRs) (CIDRs, error) {
+	var removes []*net.IPNet
+	for _, u := range used {
+		if u.Ver == c.Ver {
+			removes = append(removes, u.Net())
+		}
+	}
+	nets, err := iputils.RemoveCIDRs([]*net.IPNet{c.FirstIP().CIDR(c.Mask).Net()}, removes)
+	if err != nil {
+		return nil, err
+	}
+	return newCIDRsFromNets(nets), nil
+}
+
+// FirstFree return the free block of mask with the lowest address, ErrNoFreeSpace if not found
+func (c *CIDR) FirstFree(used CIDRs, mask int) (*CIDR, error) {
+	if mask < c.Mask || mask > c.Len()*8 {
+		return nil, newParseError(c.String(), 0, ErrMaskOutOfRange)
+	}
+	free, err := c.FreeSpace(used)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range free {
+		if f.Mask <= mask {
+			return f.FirstIP().CIDR(mask), nil
+		}
+	}
+	return nil, ErrNoFreeSpace
+}
+
+// FreeBlocks return every free block of mask sorted by address, e.g. all free /24 of 10.0.0.0/16
+func (c *CIDR) FreeBlocks(used CIDRs, mask int) (CIDRs, error) {
+	if mask < c.Mask || mask > c.Len()*8 {
+		return nil, newParseError(c.String(), 0, ErrMaskOutOfRange)
+	}
+	free, err := c.FreeSpace(used)
+	if err != nil {
+		return nil, err
+	}
+	var blocks CIDRs
+	for _, f := range free {
+		if f.Mask > mask {
+			continue
+		}
+		cs, err := f.Split(mask)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, cs...)
+	}
+	return blocks, nil
+}
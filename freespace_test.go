@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCIDR_FreeSpace(t *testing.T) {
+	parent := ParseCIDR("10.0.0.0/24")
+	used := ParseCIDRs([]string{"10.0.0.0/26", "10.0.0.130/32", "10.1.0.0/16", "2001:db8::/32"})
+	free, err := parent.FreeSpace(used)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.64/26", "10.0.0.128/31", "10.0.0.131/32", "10.0.0.132/30",
+		"10.0.0.136/29", "10.0.0.144/28", "10.0.0.160/27", "10.0.0.192/26"}, free.Strings())
+
+	free, err = parent.FreeSpace(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/24"}, free.Strings())
+
+	free, err = parent.FreeSpace(ParseCIDRs([]string{"10.0.0.0/8"}))
+	assert.NoError(t, err)
+	assert.Len(t, free, 0)
+
+	free, err = ParseCIDR("2001:db8::/62").FreeSpace(ParseCIDRs([]string{"2001:db8:0:1::/64"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::/64", "2001:db8:0:2::/63"}, free.Strings())
+}
+
+func TestCIDR_FirstFree(t *testing.T) {
+	parent := ParseCIDR("10.0.0.0/24")
+	used := ParseCIDRs([]string{"10.0.0.0/26", "10.0.0.64/27", "10.0.0.128/32"})
+	c, err := parent.FirstFree(used, 26)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.192/26", c.String())
+	c, err = parent.FirstFree(used, 27)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.96/27", c.String())
+
+	_, err = parent.FirstFree(used, 25)
+	assert.Equal(t, ErrNoFreeSpace, err)
+	_, err = parent.FirstFree(used, 16)
+	assert.Error(t, err)
+
+	c, err = ParseCIDR("2001:db8::/48").FirstFree(ParseCIDRs([]string{"2001:db8::/64"}), 64)
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8:0:1::/64", c.String())
+}
+
+func TestCIDR_FreeBlocks(t *testing.T) {
+	parent := ParseCIDR("10.0.0.0/22")
+	blocks, err := parent.FreeBlocks(ParseCIDRs([]string{"10.0.1.0/24", "10.0.2.5/32"}), 24)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/24", "10.0.3.0/24"}, blocks.Strings())
+
+	blocks, err = ParseCIDR("2001:db8::/62").FreeBlocks(ParseCIDRs([]string{"2001:db8:0:2::/64"}), 64)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::/64", "2001:db8:0:1::/64", "2001:db8:0:3::/64"}, blocks.Strings())
+}
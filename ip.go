@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"github.com/chainreactors/utils/iputils"
+	"math/big"
 	"net"
 )
 
@@ -12,6 +14,8 @@ var (
 	IPV6 = 6
 )
 
+var bigOne = big.NewInt(1)
+
 func IsIp(ip string) bool {
 	if net.ParseIP(ip) != nil {
 		return true
@@ -97,17 +101,25 @@ func DistinguishIPVersion(ip net.IP) int {
 	return 0
 }
 
-func ParseIP(s string) *IP {
+// ParseIP parse ip literal, hostname will be resolved by the optional resolver or DefaultResolver,
+// use NoDNS as resolver to parse ip literal only
+func ParseIP(s string, resolver ...Resolver) *IP {
+	if ip := parseIPLiteral(s); ip != nil {
+		return ip
+	}
+	i, err := ParseHostToIP(s, resolver...)
+	if err != nil {
+		return nil
+	}
+	return i
+}
+
+// parseIPLiteral version is decided by the notation, ipv4-mapped ::ffff:1.2.3.4 is still ipv6
+func parseIPLiteral(s string) *IP {
 	ip := net.ParseIP(s)
 	if ip == nil {
-		i, err := ParseHostToIP(s)
-		if err != nil {
-			return nil
-		} else {
-			return i
-		}
+		return nil
 	}
-
 	for i := 0; i < len(s); i++ {
 		switch s[i] {
 		case '.':
@@ -125,32 +137,22 @@ func ParseIP(s string) *IP {
 func NewIP(ip net.IP) *IP {
 	if len(ip) == net.IPv4len {
 		return &IP{IP: ip, Ver: IPV4}
-	} else if ip[0] == 0 {
+	} else if ip.To4() != nil {
 		return &IP{IP: ip.To4(), Ver: IPV4}
 	} else {
 		return &IP{IP: ip, Ver: IPV6}
 	}
 }
 
-// ParseHostToIP parse host to ip and validate ip format
-func ParseHostToIP(target string) (*IP, error) {
-	iprecords, err := net.LookupIP(ParseHost(target))
+// ParseHostToIP parse host to ip and validate ip format, only the first record is returned
+func ParseHostToIP(target string, resolver ...Resolver) (*IP, error) {
+	ips, err := ParseHostToIPs(target, resolver...)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to resolve domain name:" + target + ". SKIPPED!")
-	}
-
-	for _, ip := range iprecords {
-		if ip != nil {
-			//Log.Important("parse domain SUCCESS, map " + target + " to " + ip.String())
-			switch DistinguishIPVersion(ip) {
-			case 4:
-				return &IP{ip.To4(), IPV4, target}, nil
-			case 6:
-				return &IP{ip.To16(), IPV6, target}, nil
-			}
-		}
+		return nil, err
 	}
-	return nil, fmt.Errorf("not found Ip address")
+	ip := ips[0]
+	ip.Host = target
+	return ip, nil
 }
 
 type IP struct {
@@ -176,7 +178,30 @@ func (ip *IP) Int() uint {
 	return 0
 }
 
+// BigInt returns the integer representation of ip, works for both ipv4 and ipv6
+func (ip *IP) BigInt() *big.Int {
+	i, _, err := iputils.IPToInteger(ip.IP)
+	if err != nil {
+		return new(big.Int)
+	}
+	return i
+}
+
+// newIPFromBig convert integer back to ip of ver
+func newIPFromBig(i *big.Int, ver int) *IP {
+	if ver == IPV4 {
+		return &IP{IP: iputils.IntegerToIP(i, 32), Ver: IPV4}
+	}
+	return &IP{IP: iputils.IntegerToIP(i, 128), Ver: IPV6}
+}
+
+// String net.IP prints ipv4-mapped address as ipv4, ipv6 ip keeps its ::ffff: prefix
 func (ip *IP) String() string {
+	if ip.Ver == IPV6 && len(ip.IP) == net.IPv6len {
+		if ip4 := ip.IP.To4(); ip4 != nil {
+			return "::ffff:" + ip4.String()
+		}
+	}
 	return ip.IP.String()
 }
 
@@ -250,10 +275,19 @@ func (ip *IP) Next() *IP {
 	return ip
 }
 
-// ParseIPs parse string to ip , auto skip wrong ip
+// ParseIPs parse string to ip , auto skip wrong ip, ip range will be expanded,
+// range of more than MaxExpandIPs ips is skipped
 func ParseIPs(input []string) IPs {
 	var ips IPs
 	for _, ip := range input {
+		if rs, err := ParseIPRange(ip); err == nil {
+			if is, err := expandIPRanges(ip, rs); err == nil {
+				ips = append(ips, is...)
+			}
+			continue
+		} else if isRangeTooLarge(err) {
+			continue
+		}
 		i := ParseIP(ip)
 		if i == nil {
 			continue
@@ -297,36 +331,7 @@ func (is IPs) Strings() []string {
 	return s
 }
 
+// Approx collapse ips of every ipv4 /24 or ipv6 /120 to the smallest spanning cidr, see Aggregate
 func (is IPs) Approx() CIDRs {
-	cidrMap := make(map[string]*CIDR)
-
-	for _, ip := range is {
-		if n, ok := cidrMap[ip.Mask(24).String()]; ok {
-			var baseNet byte
-			var nowN, newN byte
-			for i := 8; i > 0; i-- {
-				nowN = n.IP.IP[3] & (1 << uint(i-1)) >> uint(i-1)
-				newN = ip.IP[3] & (1 << uint(i-1)) >> uint(i-1)
-				if nowN&newN == 1 {
-					baseNet += 1 << uint(i-1)
-				}
-				if nowN^newN == 1 {
-					n.Mask = 32 - i
-					n.IP.IP[3] = baseNet
-					break
-				}
-			}
-		} else {
-			cidrMap[ip.Mask(24).String()] = NewCIDR(ip.String(), 32)
-		}
-	}
-
-	approxed := make(CIDRs, len(cidrMap))
-	var index int
-	for _, cidr := range cidrMap {
-		approxed[index] = cidr
-		index++
-	}
-
-	return approxed
+	return is.Aggregate(AggregateOptions{IPv4Prefix: 24, IPv6Prefix: 120, Tight: true}).CIDRs()
 }
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net"
 	"sort"
 	"testing"
 )
@@ -60,3 +62,17 @@ func TestIP_Mask(t *testing.T) {
 	m := i.Mask(24)
 	println(i.String(), m.String())
 }
+
+func TestNewIP(t *testing.T) {
+	// ipv6 starting with zero byte is not ipv4, only 16 bytes ipv4-mapped address is
+	ip := NewIP(net.ParseIP("::1"))
+	assert.Equal(t, IPV6, ip.Ver)
+	assert.Equal(t, "::1", ip.String())
+	ip = NewIP(net.ParseIP("::2001:db8"))
+	assert.Equal(t, IPV6, ip.Ver)
+
+	ip = NewIP(net.ParseIP("10.0.0.1"))
+	assert.Equal(t, IPV4, ip.Ver)
+	assert.Len(t, ip.IP, net.IPv4len)
+	assert.Equal(t, IPV4, NewIP(net.IP{10, 0, 0, 1}).Ver)
+}
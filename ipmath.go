@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"math/big"
+)
+
+// maxIP the largest integer of ip version
+func maxIP(ver int) *big.Int {
+	bits := uint(128)
+	if ver == IPV4 {
+		bits = 32
+	}
+	return new(big.Int).Sub(new(big.Int).Lsh(bigOne, bits), bigOne)
+}
+
+// Add return a new ip of ip + n, n can be negative, return ErrIPOverflow if result is out of address space.
+// hostname is not kept
+func (ip *IP) Add(n *big.Int) (*IP, error) {
+	i := new(big.Int).Add(ip.BigInt(), n)
+	if i.Sign() < 0 || i.Cmp(maxIP(ip.Ver)) > 0 {
+		return nil, ErrIPOverflow
+	}
+	return newIPFromBig(i, ip.Ver), nil
+}
+
+func (ip *IP) AddInt(n int64) (*IP, error) {
+	return ip.Add(big.NewInt(n))
+}
+
+// Sub return a new ip of ip - n
+func (ip *IP) Sub(n *big.Int) (*IP, error) {
+	return ip.Add(new(big.Int).Neg(n))
+}
+
+func (ip *IP) SubInt(n int64) (*IP, error) {
+	return ip.Add(big.NewInt(-n))
+}
+
+// Prev return a new ip before ip, unlike Next, ip is not modified
+func (ip *IP) Prev() (*IP, error) {
+	return ip.AddInt(-1)
+}
+
+// Succ return a new ip after ip, unlike Next, ip is not modified
+func (ip *IP) Succ() (*IP, error) {
+	return ip.AddInt(1)
+}
+
+// Distance return other - ip, negative if other is before ip
+func (ip *IP) Distance(other *IP) (*big.Int, error) {
+	if ip.Ver != other.Ver {
+		return nil, ErrIPVersion
+	}
+	return new(big.Int).Sub(other.BigInt(), ip.BigInt()), nil
+}
+
+// Cmp total order of ips, ipv4 is less than ipv6, return -1, 0 or 1
+func (ip *IP) Cmp(other *IP) int {
+	if ip.Ver != other.Ver {
+		if ip.Ver < other.Ver {
+			return -1
+		}
+		return 1
+	}
+	return ip.BigInt().Cmp(other.BigInt())
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestIP_Arithmetic(t *testing.T) {
+	ip := ParseIP("10.0.0.255")
+	next, err := ip.AddInt(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.1.0", next.String())
+	assert.Equal(t, "10.0.0.255", ip.String())
+
+	prev, err := next.Prev()
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.255", prev.String())
+	back, _ := ip.SubInt(256)
+	assert.Equal(t, "9.255.255.255", back.String())
+	succ, _ := ip.Succ()
+	assert.Equal(t, "10.0.1.0", succ.String())
+
+	_, err = ParseIP("0.0.0.0").Prev()
+	assert.Equal(t, ErrIPOverflow, err)
+	_, err = ParseIP("255.255.255.255").AddInt(1)
+	assert.True(t, errors.Is(err, ErrIPOverflow))
+
+	v6 := ParseIP("2001:db8::ffff:ffff:ffff:ffff")
+	next, _ = v6.AddInt(1)
+	assert.Equal(t, "2001:db8:0:1::", next.String())
+	far, _ := v6.Add(new(big.Int).Lsh(bigOne, 64))
+	assert.Equal(t, "2001:db8:0:1:ffff:ffff:ffff:ffff", far.String())
+	_, err = ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff").Succ()
+	assert.True(t, errors.Is(err, ErrIPOverflow))
+	back, _ = next.Sub(big.NewInt(2))
+	assert.Equal(t, "2001:db8::ffff:ffff:ffff:fffe", back.String())
+
+	d, err := ParseIP("10.0.0.1").Distance(ParseIP("10.0.1.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(256), d.Int64())
+	d, _ = ParseIP("10.0.1.1").Distance(ParseIP("10.0.0.1"))
+	assert.Equal(t, int64(-256), d.Int64())
+	_, err = ParseIP("10.0.0.1").Distance(ParseIP("::1"))
+	assert.Equal(t, ErrIPVersion, err)
+
+	assert.Equal(t, -1, ParseIP("10.0.0.1").Cmp(ParseIP("10.0.0.2")))
+	assert.Equal(t, 0, ParseIP("10.0.0.1").Cmp(ParseIP("10.0.0.1")))
+	assert.Equal(t, -1, ParseIP("255.255.255.255").Cmp(ParseIP("::")))
+	assert.Equal(t, 1, ParseIP("::").Cmp(ParseIP("0.0.0.0")))
+}
+
+func TestCIDR_SplitIPv6(t *testing.T) {
+	cs, err := ParseCIDR("2001:db8::/32").Split(34)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::/34", "2001:db8:4000::/34", "2001:db8:8000::/34", "2001:db8:c000::/34"}, cs.Strings())
+
+	cs, err = ParseCIDR("0.0.0.0/0").Split(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "192.0.0.0/2", cs[3].String())
+
+	_, err = ParseCIDR("2001:db8::/32").Split(64)
+	assert.Error(t, err)
+	_, err = ParseCIDR("10.0.0.0/24").Split(33)
+	assert.True(t, errors.Is(err, ErrMaskOutOfRange))
+}
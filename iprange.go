@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/chainreactors/utils/iputils"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	// MaxIPRanges limit count of continuous ranges of a nmap style range, e.g. 1-254.1-254.1-254.1-254 is more than 16M ranges
+	MaxIPRanges = 1 << 16
+	// MaxExpandIPs limit count of ips expanded by ParseIPs and ParseIPsE, iterate CIDRs of larger ranges instead
+	MaxExpandIPs int64 = 1 << 24
+)
+
+// IPRange continuous ip addresses from Start to End, both included
+type IPRange struct {
+	Start *IP
+	End   *IP
+}
+
+func NewIPRange(start, end *IP) (*IPRange, error) {
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("invalid ip range")
+	}
+	if start.Ver != end.Ver {
+		return nil, fmt.Errorf("ip range %s-%s mixes ipv4 and ipv6", start.String(), end.String())
+	}
+	if start.Compare(end) > 0 {
+		return nil, fmt.Errorf("start ip %s must be less than end ip %s", start.String(), end.String())
+	}
+	return &IPRange{Start: start, End: end}, nil
+}
+
+// IsIPRange check s is an ip range, hostname like "a-b.com" will not be treated as range
+func IsIPRange(s string) bool {
+	_, err := ParseIPRange(s)
+	return err == nil
+}
+
+// ParseIPRange parse ip range string, support:
+//
+//	10.0.0.1-10.0.0.50
+//	10.0.0.1-50
+//	192.168.0-3.1-254, nmap style octet ranges, "*" means 0-255
+//	2001:db8::1-2001:db8::ff
+//	2001:db8::1-ff
+//
+// nmap style range may be discontinuous, so IPRanges are returned,
+// ErrRangeTooLarge is returned if it is more than MaxIPRanges continuous ranges
+func ParseIPRange(s string) (IPRanges, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "-") && !strings.Contains(s, "*") {
+		return nil, fmt.Errorf("%s is not ip range", s)
+	}
+
+	if tmp := strings.Split(s, "-"); len(tmp) == 2 && IsIp(tmp[0]) {
+		if IsIp(tmp[1]) {
+			r, err := NewIPRange(ParseIP(tmp[0]), ParseIP(tmp[1]))
+			if err != nil {
+				return nil, err
+			}
+			return IPRanges{r}, nil
+		}
+		if strings.Contains(tmp[0], ":") {
+			return parseIPv6ShortRange(tmp[0], tmp[1])
+		}
+	}
+
+	return parseOctetRange(s)
+}
+
+// parseIPv6ShortRange parse 2001:db8::1-ff, replace the last hextet of start with end
+func parseIPv6ShortRange(start, end string) (IPRanges, error) {
+	ip := ParseIP(start)
+	n, err := strconv.ParseUint(end, 16, 16)
+	if ip == nil || err != nil {
+		return nil, fmt.Errorf("%s-%s is not ip range", start, end)
+	}
+	last := ip.Copy()
+	last.IP[14] = byte(n >> 8)
+	last.IP[15] = byte(n)
+	r, err := NewIPRange(ip, last)
+	if err != nil {
+		return nil, err
+	}
+	return IPRanges{r}, nil
+}
+
+// parseOctetRange parse nmap style ipv4 range, e.g. 192.168.0-3.1-254
+func parseOctetRange(s string) (IPRanges, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("%s is not ip range", s)
+	}
+
+	var octets [4][2]int
+	for i, part := range parts {
+		lo, hi, err := parseOctet(part)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not ip range, %s", s, err.Error())
+		}
+		octets[i] = [2]int{lo, hi}
+	}
+
+	// octets after k all cover 0-255, so every combination of octets[:k] is one continuous range
+	k := 3
+	for k > 0 && octets[k][0] == 0 && octets[k][1] == 255 {
+		k--
+	}
+
+	n := 1
+	for i := 0; i < k; i++ {
+		n *= octets[i][1] - octets[i][0] + 1
+	}
+	if n > MaxIPRanges {
+		return nil, newParseError(s, 0, ErrRangeTooLarge)
+	}
+
+	rs := make(IPRanges, 0, n)
+	var walk func(i int, prefix []byte)
+	walk = func(i int, prefix []byte) {
+		if i == k {
+			start := append(append(net.IP{}, prefix...), byte(octets[k][0]))
+			end := append(append(net.IP{}, prefix...), byte(octets[k][1]))
+			for j := k + 1; j < 4; j++ {
+				start = append(start, 0)
+				end = append(end, 255)
+			}
+			rs = append(rs, &IPRange{Start: &IP{IP: start, Ver: IPV4}, End: &IP{IP: end, Ver: IPV4}})
+			return
+		}
+		for o := octets[i][0]; o <= octets[i][1]; o++ {
+			walk(i+1, append(prefix, byte(o)))
+		}
+	}
+	walk(0, make([]byte, 0, 4))
+	return rs, nil
+}
+
+// isRangeTooLarge error of ParseIPRange is caused by limit, the input should not be parsed as hostname
+func isRangeTooLarge(err error) bool {
+	e, ok := err.(*ParseError)
+	return ok && e.Err == ErrRangeTooLarge
+}
+
+// expandIPRanges expand ranges of input s to ips, ErrRangeTooLarge if more than MaxExpandIPs
+func expandIPRanges(s string, rs IPRanges) (IPs, error) {
+	if rs.Count().Cmp(big.NewInt(MaxExpandIPs)) > 0 {
+		return nil, newParseError(s, 0, ErrRangeTooLarge)
+	}
+	return rs.IPs(), nil
+}
+
+func parseOctet(s string) (int, int, error) {
+	if s == "*" {
+		return 0, 255, nil
+	}
+	var lo, hi int
+	var err error
+	if tmp := strings.Split(s, "-"); len(tmp) == 2 {
+		if lo, err = strconv.Atoi(tmp[0]); err != nil {
+			return 0, 0, err
+		}
+		if hi, err = strconv.Atoi(tmp[1]); err != nil {
+			return 0, 0, err
+		}
+	} else if lo, err = strconv.Atoi(s); err != nil {
+		return 0, 0, err
+	} else {
+		hi = lo
+	}
+	if lo < 0 || hi > 255 || lo > hi {
+		return 0, 0, fmt.Errorf("octet %s out of range", s)
+	}
+	return lo, hi, nil
+}
+
+func (r *IPRange) String() string {
+	return r.Start.String() + "-" + r.End.String()
+}
+
+func (r *IPRange) Count() *big.Int {
+	count, _ := r.Start.Distance(r.End)
+	return count.Add(count, bigOne)
+}
+
+func (r *IPRange) ContainsIP(ip *IP) bool {
+	return ip.Ver == r.Start.Ver && r.Start.Compare(ip) <= 0 && r.End.Compare(ip) >= 0
+}
+
+// CIDRs convert range to the minimal cidrs list
+func (r *IPRange) CIDRs() CIDRs {
+	nets, err := iputils.GetCIDRFromIPRange(r.Start.IP.To16(), r.End.IP.To16())
+	if err != nil {
+		return nil
+	}
+	cs := newCIDRsFromNets(nets)
+	if r.Start.Ver == IPV6 {
+		// iputils treat ipv4-mapped range as ipv4, move it back to ::ffff:0:0/96
+		for i, c := range cs {
+			if c.Ver == IPV4 {
+				cs[i] = (&IP{IP: c.IP.IP.To16(), Ver: IPV6}).CIDR(c.Mask + 96)
+			}
+		}
+	}
+	return cs
+}
+
+// IPs expand range to every ip in it without limit, iterate CIDRs of large range instead
+func (r *IPRange) IPs() IPs {
+	var ips IPs
+	for ip := r.Start; ip != nil && ip.Cmp(r.End) <= 0; ip, _ = ip.Succ() {
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
+type IPRanges []*IPRange
+
+func (rs IPRanges) Strings() []string {
+	s := make([]string, len(rs))
+	for i, r := range rs {
+		s[i] = r.String()
+	}
+	return s
+}
+
+func (rs IPRanges) Count() *big.Int {
+	sum := new(big.Int)
+	for _, r := range rs {
+		sum.Add(sum, r.Count())
+	}
+	return sum
+}
+
+// CIDRs convert ranges to the minimal and sorted cidrs list
+func (rs IPRanges) CIDRs() CIDRs {
+	set := NewIPSet(nil)
+	for _, r := range rs {
+		for _, c := range r.CIDRs() {
+			set.AddCIDR(c)
+		}
+	}
+	return set.CIDRs()
+}
+
+func (rs IPRanges) IPs() IPs {
+	var ips IPs
+	for _, r := range rs {
+		ips = append(ips, r.IPs()...)
+	}
+	return ips
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseIPRange(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{"10.0.0.1-10.0.0.50", []string{"10.0.0.1-10.0.0.50"}},
+		{"10.0.0.1-50", []string{"10.0.0.1-10.0.0.50"}},
+		{"192.168.0-1.1-254", []string{"192.168.0.1-192.168.0.254", "192.168.1.1-192.168.1.254"}},
+		{"10.0-3.*.*", []string{"10.0.0.0-10.3.255.255"}},
+		{"2001:db8::1-2001:db8::ff", []string{"2001:db8::1-2001:db8::ff"}},
+		{"2001:db8::1-ff", []string{"2001:db8::1-2001:db8::ff"}},
+	}
+	for _, tc := range testCases {
+		rs, err := ParseIPRange(tc.input)
+		if assert.NoError(t, err, tc.input) {
+			assert.Equal(t, tc.expected, rs.Strings(), tc.input)
+		}
+	}
+
+	for _, s := range []string{"10.0.0.1", "10.0.0.50-1", "10.0.0.1-256", "a-b.example.com", "10.0.0.1-2001:db8::1"} {
+		_, err := ParseIPRange(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestIPRange_CIDRs(t *testing.T) {
+	rs, _ := ParseIPRange("10.0.0.1-10.0.0.50")
+	assert.Equal(t, []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/29", "10.0.0.16/28", "10.0.0.32/28", "10.0.0.48/31", "10.0.0.50/32"}, rs.CIDRs().Strings())
+	assert.Equal(t, "50", rs.Count().String())
+
+	rs, _ = ParseIPRange("192.168.0-3.*")
+	assert.Equal(t, []string{"192.168.0.0/22"}, rs.CIDRs().Strings())
+	assert.Equal(t, "1024", rs.Count().String())
+
+	assert.Equal(t, "10.0.0.0/24", ParseCIDR("10.0.0.0-10.0.0.255").String())
+	assert.Nil(t, ParseCIDR("10.0.0.1-10.0.0.50"))
+	assert.Equal(t, []string{"10.0.0.0/31", "10.0.0.2/32", "192.168.1.0/24"}, ParseCIDRs([]string{"10.0.0.0-2", "192.168.1.0/24"}).Strings())
+	assert.Len(t, ParseIPs([]string{"192.168.0-1.1-10"}), 20)
+
+	rs, _ = ParseIPRange("::ffff:10.0.0.0-::ffff:10.0.0.255")
+	assert.Equal(t, []string{"::ffff:10.0.0.0/120"}, rs.CIDRs().Strings())
+	assert.Equal(t, IPV6, rs.CIDRs()[0].Ver)
+}
+
+func TestParseIPRange_Limit(t *testing.T) {
+	start := time.Now()
+	_, err := ParseIPRange("1-254.1-254.1-254.1-254")
+	assert.True(t, errors.Is(err, ErrRangeTooLarge))
+	assert.Nil(t, ParseCIDR("1-254.1-254.1-254.1-254", NoDNS))
+	_, err = ParseCIDRE("1-254.1-254.1-254.1-254", NoDNS)
+	assert.True(t, errors.Is(err, ErrRangeTooLarge))
+
+	// the whole ipv4 space is one range, but too many ips to expand
+	rs, err := ParseIPRange("*.*.*.*")
+	assert.NoError(t, err)
+	assert.Equal(t, "4294967296", rs.Count().String())
+	assert.Empty(t, ParseIPs([]string{"*.*.*.*"}))
+	_, err = ParseIPsE([]string{"10.0.0.1", "*.*.*.*"})
+	assert.True(t, errors.Is(err.(ParseErrors)[0], ErrRangeTooLarge))
+	assert.Equal(t, 2, err.(ParseErrors)[0].Line)
+	assert.Len(t, ParseCIDRs([]string{"*.*.*.*", "1-254.1-254.1-254.1-254"}), 1)
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	assert.Len(t, ParseIPs([]string{"10.0-1.0-255.1-254"}), 2*256*254)
+}
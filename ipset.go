@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"math/big"
+	"net"
+)
+
+// ipSetNode node of binary radix trie, full means the whole prefix of this node is in set
+type ipSetNode struct {
+	children [2]*ipSetNode
+	full     bool
+}
+
+func (n *ipSetNode) add(ip net.IP, depth, mask int) {
+	if n.full {
+		return
+	}
+	if depth == mask {
+		n.full = true
+		n.children = [2]*ipSetNode{}
+		return
+	}
+
+	b := ipBit(ip, depth)
+	if n.children[b] == nil {
+		n.children[b] = &ipSetNode{}
+	}
+	n.children[b].add(ip, depth+1, mask)
+
+	// merge siblings, keep the trie minimal
+	if n.children[0] != nil && n.children[0].full && n.children[1] != nil && n.children[1].full {
+		n.full = true
+		n.children = [2]*ipSetNode{}
+	}
+}
+
+// remove return true if the node becomes empty and should be removed by parent
+func (n *ipSetNode) remove(ip net.IP, depth, mask int) bool {
+	if depth == mask {
+		return true
+	}
+	if n.full {
+		n.full = false
+		n.children = [2]*ipSetNode{{full: true}, {full: true}}
+	}
+
+	b := ipBit(ip, depth)
+	if n.children[b] != nil && n.children[b].remove(ip, depth+1, mask) {
+		n.children[b] = nil
+	}
+	return n.children[0] == nil && n.children[1] == nil
+}
+
+func (n *ipSetNode) contains(ip net.IP, depth, mask int) bool {
+	for ; n != nil; depth++ {
+		if n.full {
+			return true
+		}
+		if depth == mask {
+			return false
+		}
+		n = n.children[ipBit(ip, depth)]
+	}
+	return false
+}
+
+func (n *ipSetNode) walk(buf net.IP, depth int, fn func(ip net.IP, mask int)) {
+	if n.full {
+		fn(buf, depth)
+		return
+	}
+	for b, child := range n.children {
+		if child == nil {
+			continue
+		}
+		if b == 1 {
+			buf[depth/8] |= 1 << uint(7-depth%8)
+		}
+		child.walk(buf, depth+1, fn)
+		buf[depth/8] &^= 1 << uint(7-depth%8)
+	}
+}
+
+// ipBit return the i-th bit of ip, from the most significant bit
+func ipBit(ip net.IP, i int) int {
+	return int(ip[i/8]>>uint(7-i%8)) & 1
+}
+
+// ipBytes return 4 bytes for ipv4 and 16 bytes for ipv6
+func ipBytes(ip *IP) net.IP {
+	if ip.Ver == IPV4 {
+		return ip.IP.To4()
+	}
+	return ip.IP.To16()
+}
+
+// IPSet set of ipv4 and ipv6 addresses backed by binary radix trie,
+// lookup cost only depends on the address length, not the count of cidrs.
+// IPSet is not safe for concurrent modification
+type IPSet struct {
+	v4 *ipSetNode
+	v6 *ipSetNode
+}
+
+func NewIPSet(cs CIDRs) *IPSet {
+	s := &IPSet{v4: &ipSetNode{}, v6: &ipSetNode{}}
+	for _, c := range cs {
+		s.AddCIDR(c)
+	}
+	return s
+}
+
+// IPSet build IPSet from cidrs
+func (cs CIDRs) IPSet() *IPSet {
+	return NewIPSet(cs)
+}
+
+func (s *IPSet) root(ver int) *ipSetNode {
+	if ver == IPV4 {
+		return s.v4
+	}
+	return s.v6
+}
+
+func (s *IPSet) AddCIDR(c *CIDR) {
+	s.root(c.Ver).add(ipBytes(c.IP), 0, c.Mask)
+}
+
+func (s *IPSet) AddIP(ip *IP) {
+	s.root(ip.Ver).add(ipBytes(ip), 0, ip.Len()*8)
+}
+
+func (s *IPSet) RemoveCIDR(c *CIDR) {
+	if s.root(c.Ver).remove(ipBytes(c.IP), 0, c.Mask) {
+		if c.Ver == IPV4 {
+			s.v4 = &ipSetNode{}
+		} else {
+			s.v6 = &ipSetNode{}
+		}
+	}
+}
+
+func (s *IPSet) RemoveIP(ip *IP) {
+	s.RemoveCIDR(ip.CIDR(ip.Len() * 8))
+}
+
+func (s *IPSet) ContainsIP(ip *IP) bool {
+	return s.root(ip.Ver).contains(ipBytes(ip), 0, ip.Len()*8)
+}
+
+// ContainsCIDR return true only if every ip of cidr is in set
+func (s *IPSet) ContainsCIDR(c *CIDR) bool {
+	return s.root(c.Ver).contains(ipBytes(c.IP), 0, c.Mask)
+}
+
+// CIDRs return the minimal and sorted cidrs of set, ipv4 first
+func (s *IPSet) CIDRs() CIDRs {
+	var cs CIDRs
+	collect := func(ver int) func(ip net.IP, mask int) {
+		return func(ip net.IP, mask int) {
+			cs = append(cs, (&IP{IP: ip, Ver: ver}).CIDR(mask))
+		}
+	}
+	s.v4.walk(make(net.IP, net.IPv4len), 0, collect(IPV4))
+	s.v6.walk(make(net.IP, net.IPv6len), 0, collect(IPV6))
+	return cs
+}
+
+// Count return the count of ips in set
+func (s *IPSet) Count() *big.Int {
+	count := new(big.Int)
+	for _, c := range s.CIDRs() {
+		count.Add(count, c.Count())
+	}
+	return count
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIPSet(t *testing.T) {
+	s := NewIPSet(ParseCIDRs([]string{"10.0.0.0/25", "10.0.0.128/25", "192.168.1.1", "2001:db8::/48"}))
+	assert.Equal(t, []string{"10.0.0.0/24", "192.168.1.1/32", "2001:db8::/48"}, s.CIDRs().Strings())
+	assert.True(t, s.ContainsIP(ParseIP("10.0.0.200")))
+	assert.True(t, s.ContainsIP(ParseIP("2001:db8:0:ffff::1")))
+	assert.False(t, s.ContainsIP(ParseIP("10.0.1.1")))
+	assert.False(t, s.ContainsIP(ParseIP("2001:db9::1")))
+	assert.True(t, s.ContainsCIDR(ParseCIDR("10.0.0.64/26")))
+	assert.False(t, s.ContainsCIDR(ParseCIDR("10.0.0.0/23")))
+
+	s.RemoveIP(ParseIP("10.0.0.5"))
+	s.RemoveCIDR(ParseCIDR("2001:db8::/49"))
+	assert.False(t, s.ContainsIP(ParseIP("10.0.0.5")))
+	assert.True(t, s.ContainsIP(ParseIP("10.0.0.4")))
+	assert.Equal(t, []string{"10.0.0.0/30", "10.0.0.4/32", "10.0.0.6/31", "10.0.0.8/29", "10.0.0.16/28", "10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/25", "192.168.1.1/32", "2001:db8:0:8000::/49"}, s.CIDRs().Strings())
+
+	s.AddIP(ParseIP("10.0.0.5"))
+	s.RemoveCIDR(ParseIP("0.0.0.0").CIDR(0))
+	assert.Equal(t, []string{"2001:db8:0:8000::/49"}, s.CIDRs().Strings())
+
+	// ipv4-mapped ipv6 is not mixed with ipv4
+	s = NewIPSet(CIDRs{ParseCIDR("::ffff:0:0/96")})
+	assert.Equal(t, []string{"::ffff:0.0.0.0/96"}, s.CIDRs().Strings())
+	assert.Equal(t, IPV6, s.CIDRs()[0].Ver)
+	assert.True(t, s.ContainsIP(ParseIP("::ffff:10.0.0.1")))
+	assert.False(t, s.ContainsIP(ParseIP("10.0.0.1")))
+}
+
+func BenchmarkIPSet_ContainsIP(b *testing.B) {
+	var ss []string
+	for i := 0; i < 100000; i++ {
+		ss = append(ss, fmt.Sprintf("%d.%d.%d.0/24", 10+i>>16, (i>>8)&0xff, i&0xff))
+	}
+	s := NewIPSet(ParseCIDRs(ss))
+	ip := ParseIP("11.200.3.4")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.ContainsIP(ip)
+	}
+}
@@ -183,7 +183,8 @@ PreLoop:
 			}
 
 			// Only remove CIDR if it is contained in the subnet we are allowing.
-			if allowCIDR.Contains(remove.IP.Mask(remove.Mask)) {
+			// equal CIDRs are handled by the next branch, removeCIDR requires a strict superset.
+			if allowCIDR.Contains(remove.IP.Mask(remove.Mask)) && maskSize(allowCIDR) < maskSize(remove) {
 				nets, err := removeCIDR(allowCIDR, remove)
 				if err != nil {
 					return nil, err
@@ -206,6 +207,11 @@ PreLoop:
 	return allowCIDRs, nil
 }
 
+func maskSize(ipnet *net.IPNet) int {
+	size, _ := ipnet.Mask.Size()
+	return size
+}
+
 func removeCIDR(allowCIDR, removeCIDR *net.IPNet) ([]*net.IPNet, error) {
 	var allowIsIpv4, removeIsIpv4 bool
 	var allowBitLen int
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+)
+
+// IPIterator pull style ip iterator, Next returns false when exhausted
+type IPIterator interface {
+	Next() (*IP, bool)
+}
+
+// AddrIterator pull style addr iterator, Next returns false when exhausted
+type AddrIterator interface {
+	Next() (*Addr, bool)
+}
+
+// RangeIPIterator push ips of iterator to channel until iterator exhausted or ctx done, channel will be closed in both cases
+func RangeIPIterator(ctx context.Context, it IPIterator) chan *IP {
+	ch := make(chan *IP)
+	go func() {
+		defer close(ch)
+		for {
+			ip, ok := it.Next()
+			if !ok {
+				return
+			}
+			select {
+			case ch <- ip:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// RangeAddrIterator push addrs of iterator to channel until iterator exhausted or ctx done, channel will be closed in both cases
+func RangeAddrIterator(ctx context.Context, it AddrIterator) chan *Addr {
+	ch := make(chan *Addr)
+	go func() {
+		defer close(ch)
+		for {
+			addr, ok := it.Next()
+			if !ok {
+				return
+			}
+			select {
+			case ch <- addr:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// CIDRIterator iterate every ip of cidr, owns its cur/max state, so the cidr will not be modified
+type CIDRIterator struct {
+	first *IP
+	curIP *IP
+	cur   *big.Int
+	max   *big.Int
+}
+
+func (it *CIDRIterator) Next() (*IP, bool) {
+	if it.cur.Cmp(it.max) >= 0 {
+		return nil, false
+	}
+	if it.cur.Sign() != 0 {
+		it.curIP.Next()
+	}
+	it.cur.Add(it.cur, bigOne)
+	return it.curIP.Copy(), true
+}
+
+func (it *CIDRIterator) Reset() {
+	it.curIP = it.first.Copy()
+	it.cur = new(big.Int)
+}
+
+// CIDRsIterator iterate cidrs one by one
+type CIDRsIterator struct {
+	cs    CIDRs
+	index int
+	cur   *CIDRIterator
+}
+
+func (it *CIDRsIterator) Next() (*IP, bool) {
+	for it.index < len(it.cs) {
+		if it.cur == nil {
+			it.cur = it.cs[it.index].Iterator()
+		}
+		if ip, ok := it.cur.Next(); ok {
+			return ip, true
+		}
+		it.cur = nil
+		it.index++
+	}
+	return nil, false
+}
+
+// SprayIterator take one ip from every cidr in turn
+type SprayIterator struct {
+	iters []*CIDRIterator
+	index int
+}
+
+func (it *SprayIterator) Next() (*IP, bool) {
+	for len(it.iters) > 0 {
+		it.index %= len(it.iters)
+		if ip, ok := it.iters[it.index].Next(); ok {
+			it.index++
+			return ip, true
+		}
+		it.iters = append(it.iters[:it.index], it.iters[it.index+1:]...)
+	}
+	return nil, false
+}
+
+// AddrsIterator iterate ips * ports of AddrsGenerator
+type AddrsIterator struct {
+	ips    IPs
+	ports  []string
+	byPort bool
+	outer  int
+	inner  int
+}
+
+func (it *AddrsIterator) Next() (*Addr, bool) {
+	outerLen, innerLen := len(it.ips), len(it.ports)
+	if it.byPort {
+		outerLen, innerLen = innerLen, outerLen
+	}
+	if innerLen == 0 || it.outer >= outerLen {
+		return nil, false
+	}
+
+	var addr *Addr
+	if it.byPort {
+		addr = &Addr{it.ips[it.inner], it.ports[it.outer]}
+	} else {
+		addr = &Addr{it.ips[it.outer], it.ports[it.inner]}
+	}
+	it.inner++
+	if it.inner >= innerLen {
+		it.inner = 0
+		it.outer++
+	}
+	return addr, true
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCIDR_Iterator(t *testing.T) {
+	c := ParseCIDR("192.168.1.0/30")
+	it := c.Iterator()
+	var ips []string
+	for ip, ok := it.Next(); ok; ip, ok = it.Next() {
+		ips = append(ips, ip.String())
+	}
+	assert.Equal(t, []string{"192.168.1.0", "192.168.1.1", "192.168.1.2", "192.168.1.3"}, ips)
+	assert.Equal(t, "0", c.cur.String(), "iterator should not modify cidr")
+	assert.Equal(t, "192.168.1.0", c.Next().String())
+}
+
+func TestCIDRs_SprayIterator(t *testing.T) {
+	cs := CIDRs{ParseCIDR("10.0.0.0/31"), ParseCIDR("10.0.1.0/30")}
+	var ips []string
+	for ip := range cs.SprayRange() {
+		ips = append(ips, ip.String())
+	}
+	assert.Equal(t, []string{"10.0.0.0", "10.0.1.0", "10.0.0.1", "10.0.1.1", "10.0.1.2", "10.0.1.3"}, ips)
+
+	// spray range can be called again, cidrs are not consumed
+	var n int
+	for range cs.SprayRange() {
+		n++
+	}
+	assert.Equal(t, 6, n)
+}
+
+func TestCIDRs_RangeWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := ParseCIDRs([]string{"10.0.0.0/8", "2001:db8::/32"}).RangeWithContext(ctx)
+	<-ch
+	<-ch
+	cancel()
+	for range ch {
+	}
+}
+
+func TestAddrsGenerator_Iterator(t *testing.T) {
+	as := &AddrsGenerator{IPs: ParseIPs([]string{"10.0.0.1", "10.0.0.2"}), Ports: []string{"80", "443"}}
+	var byIP, byPort []string
+	for addr := range as.GenerateWithIP() {
+		byIP = append(byIP, addr.String())
+	}
+	it := as.IteratorWithPort()
+	for addr, ok := it.Next(); ok; addr, ok = it.Next() {
+		byPort = append(byPort, addr.String())
+	}
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.1:443", "10.0.0.2:80", "10.0.0.2:443"}, byIP)
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.1:443", "10.0.0.2:443"}, byPort)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := as.GenerateWithPortContext(ctx)
+	<-ch
+	cancel()
+	for range ch {
+	}
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"github.com/chainreactors/utils/fileutils"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// LoadedTargets targets loaded from files, ips, cidrs and ranges are merged into CIDRs, host:port and urls become Addrs
+type LoadedTargets struct {
+	CIDRs CIDRs
+	Addrs Addrs
+}
+
+// TargetLoader load targets from files, zero value is ready to use
+type TargetLoader struct {
+	// ExcludeFiles files of ips, cidrs, ranges or host:port to exclude
+	ExcludeFiles []string
+	// Resolver resolve hostnames, DefaultResolver is used if nil
+	Resolver Resolver
+}
+
+// LoadTargets load target files with DefaultResolver, see TargetLoader.Load
+func LoadTargets(paths ...string) (*LoadedTargets, error) {
+	return (&TargetLoader{}).Load(paths...)
+}
+
+// loadedLine one parsed line, exactly one of cidrs and addr is set
+type loadedLine struct {
+	cidrs   CIDRs
+	addr    *Addr
+	exclude bool
+}
+
+// Load load target files, blank lines and "#" comments are skipped, "!" prefixed lines are exclusions.
+// each line can be ip, cidr, ip range, hostname, url or host:port.
+// results are deduplicated, error of reading file is returned directly,
+// wrong lines are collected as ParseErrors with file and line number, valid lines are still loaded
+func (l *TargetLoader) Load(paths ...string) (*LoadedTargets, error) {
+	var errs ParseErrors
+	var lines []*loadedLine
+	load := func(filename string, exclude bool) error {
+		content, err := fileutils.LoadFileLines(filename)
+		if err != nil {
+			return err
+		}
+		forEachLine(content, func(line string, lineno int) {
+			parsed, err := l.parseLine(line)
+			if err != nil {
+				e := withLine(err, lineno)
+				e.File = filename
+				errs = append(errs, e)
+				return
+			}
+			parsed.exclude = parsed.exclude || exclude
+			lines = append(lines, parsed)
+		})
+		return nil
+	}
+	for _, filename := range paths {
+		if err := load(filename, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, filename := range l.ExcludeFiles {
+		if err := load(filename, true); err != nil {
+			return nil, err
+		}
+	}
+
+	include, exclude := NewIPSet(nil), NewIPSet(nil)
+	excludeAddrs := make(map[string]bool)
+	for _, line := range lines {
+		switch {
+		case line.exclude && line.addr != nil:
+			excludeAddrs[line.addr.String()] = true
+		case line.exclude:
+			for _, c := range line.cidrs {
+				exclude.AddCIDR(c)
+			}
+		case line.addr == nil:
+			for _, c := range line.cidrs {
+				include.AddCIDR(c)
+			}
+		}
+	}
+	for _, c := range exclude.CIDRs() {
+		include.RemoveCIDR(c)
+	}
+
+	targets := &LoadedTargets{CIDRs: include.CIDRs()}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		if line.exclude || line.addr == nil {
+			continue
+		}
+		key := line.addr.String()
+		if seen[key] || excludeAddrs[key] || exclude.ContainsIP(line.addr.IP) {
+			continue
+		}
+		seen[key] = true
+		targets.Addrs = append(targets.Addrs, line.addr)
+	}
+	return targets, errs.err()
+}
+
+func (l *TargetLoader) parseLine(line string) (*loadedLine, error) {
+	parsed := &loadedLine{}
+	s := line
+	if strings.HasPrefix(s, "!") {
+		parsed.exclude = true
+		s = strings.TrimSpace(s[1:])
+	}
+	offset := len(line) - len(s)
+	relocate := func(err error, pos int) error {
+		e := err.(*ParseError)
+		e.Input, e.Pos = line, offset+pos+e.Pos
+		return e
+	}
+
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil || u.Hostname() == "" {
+			return nil, newParseError(line, offset, ErrInvalidAddr)
+		}
+		ip, err := ParseIPE(u.Hostname(), l.Resolver)
+		if err != nil {
+			return nil, relocate(err, strings.Index(s, u.Hostname()))
+		}
+		port := u.Port()
+		if port == "" {
+			port = defaultSchemePorts[strings.ToLower(u.Scheme)]
+		}
+		if port == "" {
+			parsed.cidrs = CIDRs{ip.CIDR(ip.Len() * 8)}
+		} else {
+			parsed.addr = &Addr{ip, port}
+		}
+		return parsed, nil
+	}
+
+	if rs, err := ParseIPRange(s); err == nil {
+		parsed.cidrs = rs.CIDRs()
+		return parsed, nil
+	}
+
+	host, port, portPos, err := splitTarget(s)
+	if err != nil {
+		return nil, newParseError(line, offset, err)
+	}
+	if portPos == -1 {
+		c, err := ParseCIDRE(host, l.Resolver)
+		if err != nil {
+			return nil, relocate(err, strings.Index(s, host))
+		}
+		parsed.cidrs = CIDRs{c}
+		return parsed, nil
+	}
+
+	addr, err := NewAddrE(net.JoinHostPort(host, port), l.Resolver)
+	if err != nil {
+		e := err.(*ParseError)
+		e.Input, e.Pos = line, offset+strings.Index(s, host)
+		if e.Err == ErrPortRange {
+			e.Pos = offset + portPos
+		}
+		return nil, e
+	}
+	parsed.addr = addr
+	return parsed, nil
+}
+
+var defaultSchemePorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+	"ssh":   "22",
+}
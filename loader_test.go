@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, dir, name, content string) string {
+	filename := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadTargets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "targets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	targets := writeTempFile(t, dir, "targets.txt", `
+# office
+10.0.0.0/24
+10.0.0.5
+10.0.1.1-10.0.1.2
+192.168.1.10-11
+https://192.0.2.1/login
+http://192.0.2.2:8080
+ftp2://192.0.2.3
+10.0.2.1:22
+[2001:db8::1]:443
+10.0.2.1:22
+10.0.2.2:22
+!10.0.0.128/25
+web.corp:8443
+10.0.0.0/33
+10.0.3.1:99999
+`)
+	more := writeTempFile(t, dir, "more.txt", "10.0.1.3\r\n2001:db8::/126\r\nunknown.corp\r\n")
+	exclude := writeTempFile(t, dir, "exclude.txt", "10.0.1.2\n10.0.2.2:22\n")
+
+	loader := &TargetLoader{
+		ExcludeFiles: []string{exclude},
+		Resolver:     NewStaticResolver(map[string][]string{"web.corp": {"10.9.9.9"}}),
+	}
+	loaded, err := loader.Load(targets, more)
+	assert.Equal(t, []string{
+		"10.0.0.0/25", "10.0.1.1/32", "10.0.1.3/32", "192.0.2.3/32", "192.168.1.10/31", "2001:db8::/126",
+	}, loaded.CIDRs.Strings())
+	assert.Equal(t, []string{
+		"192.0.2.1:443", "192.0.2.2:8080", "10.0.2.1:22", "[2001:db8::1]:443", "10.9.9.9:8443",
+	}, addrStrings(loaded.Addrs))
+
+	errs := err.(ParseErrors)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, targets, errs[0].File)
+	assert.Equal(t, 16, errs[0].Line)
+	assert.True(t, errors.Is(errs[0], ErrMaskOutOfRange))
+	assert.Equal(t, targets+`:17: port out of range "10.0.3.1:99999" at 9`, errs[1].Error())
+	assert.Equal(t, 3, errs[2].Line)
+	assert.True(t, errors.Is(errs[2], ErrResolve))
+
+	_, err = LoadTargets(filepath.Join(dir, "not-exist.txt"))
+	assert.Error(t, err)
+}
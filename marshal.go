@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"net"
+	"strings"
+)
+
+// MarshalText canonical ip string, hostname is kept as host(ip), e.g. example.com(93.184.216.34)
+func (ip IP) MarshalText() ([]byte, error) {
+	s := ip.IP.String()
+	if ip.Host != "" && ip.Host != s {
+		s = ip.Host + "(" + s + ")"
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText parse ip or host(ip), hostname is never resolved, bare hostname returns ErrResolve
+func (ip *IP) UnmarshalText(text []byte) error {
+	i, err := parseIPText(string(text))
+	if err != nil {
+		return err
+	}
+	*ip = *i
+	return nil
+}
+
+// parseIPText parse ip literal with optional host, e.g. example.com(93.184.216.34)
+func parseIPText(s string) (*IP, error) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndex(s, "(")
+	if i == -1 || !strings.HasSuffix(s, ")") {
+		return ParseIPE(s, NoDNS)
+	}
+	ip, err := ParseIPE(s[i+1:len(s)-1], NoDNS)
+	if err != nil {
+		e := err.(*ParseError)
+		e.Input, e.Pos = s, i+1
+		return nil, e
+	}
+	ip.Host = s[:i]
+	return ip, nil
+}
+
+func (ip IP) MarshalYAML() (interface{}, error) {
+	text, err := ip.MarshalText()
+	return string(text), err
+}
+
+func (ip *IP) UnmarshalYAML(value *yaml.Node) error {
+	return ip.UnmarshalText([]byte(value.Value))
+}
+
+func (c CIDR) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText parse cidr, ip or ip range of exactly one cidr, hostname is not resolved
+func (c *CIDR) UnmarshalText(text []byte) error {
+	cidr, err := ParseCIDRE(string(text), NoDNS)
+	if err != nil {
+		return err
+	}
+	*c = *cidr
+	return nil
+}
+
+func (c CIDR) MarshalYAML() (interface{}, error) {
+	return c.String(), nil
+}
+
+func (c *CIDR) UnmarshalYAML(value *yaml.Node) error {
+	return c.UnmarshalText([]byte(value.Value))
+}
+
+func (a Addr) MarshalText() ([]byte, error) {
+	if a.IP == nil {
+		return []byte(":" + a.Port), nil
+	}
+	host, err := a.IP.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(net.JoinHostPort(string(host), a.Port)), nil
+}
+
+// UnmarshalText parse ip:port, [ipv6]:port or host(ip):port, hostname is never resolved
+func (a *Addr) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return newParseError(s, 0, ErrInvalidAddr)
+	}
+	ip, err := parseIPText(host)
+	if err != nil {
+		e := err.(*ParseError)
+		e.Input, e.Pos = s, strings.Index(s, host)+e.Pos
+		return e
+	}
+	a.IP, a.Port = ip, port
+	return nil
+}
+
+func (a Addr) MarshalYAML() (interface{}, error) {
+	text, err := a.MarshalText()
+	return string(text), err
+}
+
+func (a *Addr) UnmarshalYAML(value *yaml.Node) error {
+	return a.UnmarshalText([]byte(value.Value))
+}
+
+// MarshalJSON marshal preset as its port configs
+func (preset PortPreset) MarshalJSON() ([]byte, error) {
+	return json.Marshal(preset.Configs())
+}
+
+func (preset *PortPreset) UnmarshalJSON(data []byte) error {
+	var configs []*PortConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return err
+	}
+	*preset = *NewPortPreset(configs)
+	return nil
+}
+
+func (preset PortPreset) MarshalYAML() (interface{}, error) {
+	return preset.Configs(), nil
+}
+
+func (preset *PortPreset) UnmarshalYAML(value *yaml.Node) error {
+	var configs []*PortConfig
+	if err := value.Decode(&configs); err != nil {
+		return err
+	}
+	*preset = *NewPortPreset(configs)
+	return nil
+}
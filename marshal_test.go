@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+type marshalConfig struct {
+	IP     *IP         `json:"ip" yaml:"ip"`
+	Host   IP          `json:"host" yaml:"host"`
+	CIDR   *CIDR       `json:"cidr" yaml:"cidr"`
+	Addr   Addr        `json:"addr" yaml:"addr"`
+	Addrs  Addrs       `json:"addrs" yaml:"addrs"`
+	CIDRs  CIDRs       `json:"cidrs" yaml:"cidrs"`
+	Preset *PortPreset `json:"preset" yaml:"preset"`
+}
+
+func newMarshalConfig() *marshalConfig {
+	host := ParseIP("93.184.216.34")
+	host.Host = "example.com"
+	v6 := ParseIP("2001:db8::1")
+	v6.Host = "v6.example.com"
+	return &marshalConfig{
+		IP:     ParseIP("10.0.0.1"),
+		Host:   *host,
+		CIDR:   ParseCIDR("10.0.0.1/24"),
+		Addr:   Addr{v6, "443"},
+		Addrs:  Addrs{NewAddr("10.0.0.1:80"), {v6, "22"}},
+		CIDRs:  ParseCIDRs([]string{"10.0.0.0/8", "2001:db8::/32"}),
+		Preset: NewPortPreset([]*PortConfig{{Name: "web", Ports: []string{"80", "8000-8002"}, Tags: []string{"http"}}}),
+	}
+}
+
+func assertMarshalConfig(t *testing.T, c *marshalConfig) {
+	assert.Equal(t, "10.0.0.1", c.IP.String())
+	assert.Equal(t, IPV4, c.IP.Ver)
+	assert.Equal(t, "93.184.216.34", c.Host.String())
+	assert.Equal(t, "example.com", c.Host.Host)
+	assert.Equal(t, "10.0.0.1/24", c.CIDR.String())
+	assert.Equal(t, "10.0.0.0", c.CIDR.FirstIP().String())
+	assert.Equal(t, "[2001:db8::1]:443", c.Addr.String())
+	assert.Equal(t, "v6.example.com", c.Addr.IP.Host)
+	assert.Equal(t, []string{"10.0.0.1:80", "[2001:db8::1]:22"}, addrStrings(c.Addrs))
+	assert.Equal(t, "", c.Addrs[0].IP.Host)
+	assert.Equal(t, "v6.example.com", c.Addrs[1].IP.Host)
+	assert.Equal(t, []string{"10.0.0.0/8", "2001:db8::/32"}, c.CIDRs.Strings())
+	assert.Equal(t, []string{"80", "8000", "8001", "8002"}, c.Preset.ParsePortString("http"))
+}
+
+func TestMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(newMarshalConfig())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ip": "10.0.0.1",
+		"host": "example.com(93.184.216.34)",
+		"cidr": "10.0.0.1/24",
+		"addr": "[v6.example.com(2001:db8::1)]:443",
+		"addrs": ["10.0.0.1:80", "[v6.example.com(2001:db8::1)]:22"],
+		"cidrs": ["10.0.0.0/8", "2001:db8::/32"],
+		"preset": [{"name": "web", "ports": ["80", "8000-8002"], "tags": ["http"]}]
+	}`, string(data))
+
+	c := &marshalConfig{}
+	assert.NoError(t, json.Unmarshal(data, c))
+	assertMarshalConfig(t, c)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"cidr": "10.0.0.0/33"}`), c))
+	assert.Error(t, json.Unmarshal([]byte(`{"addr": "10.0.0.1"}`), c))
+
+	// hostnames are never resolved while decoding
+	err = json.Unmarshal([]byte(`{"ip": "example.com"}`), c)
+	assert.True(t, errors.Is(err, ErrResolve))
+	err = json.Unmarshal([]byte(`{"addr": "example.com:80"}`), c)
+	assert.True(t, errors.Is(err, ErrResolve))
+	err = json.Unmarshal([]byte(`{"ip": "example.com(example.org)"}`), c)
+	assert.True(t, errors.Is(err, ErrResolve))
+	err = json.Unmarshal([]byte(`{"cidr": "example.com/24"}`), c)
+	assert.True(t, errors.Is(err, ErrResolve))
+}
+
+func TestMarshalYAML(t *testing.T) {
+	data, err := yaml.Marshal(newMarshalConfig())
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "host: example.com(93.184.216.34)\n")
+	assert.Contains(t, string(data), "addr: '[v6.example.com(2001:db8::1)]:443'\n")
+	assert.Contains(t, string(data), "cidrs:\n    - 10.0.0.0/8\n")
+
+	c := &marshalConfig{}
+	assert.NoError(t, yaml.Unmarshal(data, c))
+	assertMarshalConfig(t, c)
+
+	assert.Error(t, yaml.Unmarshal([]byte("ip: 10.0.0.256\n"), c))
+}
@@ -0,0 +1,237 @@
+package mmdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// data types of MaxMind DB data section
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// maxDepth limit nesting of maps and arrays, protect from malformed database
+const maxDepth = 32
+
+// decoder decode values of data section, all offsets are relative to the start of buf
+type decoder struct {
+	buf []byte
+}
+
+func (d *decoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid mmdb data section, "+format, args...)
+}
+
+func (d *decoder) read(offset, size uint) ([]byte, error) {
+	if offset+size > uint(len(d.buf)) || offset+size < offset {
+		return nil, d.errorf("unexpected end of data at %d", offset)
+	}
+	return d.buf[offset : offset+size], nil
+}
+
+// decodeCtrl return type, size and the offset of payload
+func (d *decoder) decodeCtrl(offset uint) (int, uint, uint, error) {
+	b, err := d.read(offset, 1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	ctrl := b[0]
+	offset++
+
+	typ := int(ctrl >> 5)
+	if typ == typeExtended {
+		b, err = d.read(offset, 1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		typ = 7 + int(b[0])
+		offset++
+	}
+	if typ == typePointer {
+		return typ, uint(ctrl), offset, nil
+	}
+
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		b, err = d.read(offset, n)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		offset += n
+		switch n {
+		case 1:
+			size = 29 + uint(b[0])
+		case 2:
+			size = 285 + (uint(b[0])<<8 | uint(b[1]))
+		case 3:
+			size = 65821 + (uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]))
+		}
+	}
+	return typ, size, offset, nil
+}
+
+// decode value at offset, return the value and offset of the next value
+func (d *decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDepth {
+		return nil, 0, d.errorf("exceeded maximum depth %d", maxDepth)
+	}
+	typ, size, offset, err := d.decodeCtrl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == typePointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		v, _, err := d.decode(pointer, depth+1)
+		return v, next, err
+	}
+
+	switch typ {
+	case typeMap:
+		return d.decodeMap(size, offset, depth)
+	case typeArray:
+		return d.decodeArray(size, offset, depth)
+	case typeBool:
+		if size > 1 {
+			return nil, 0, d.errorf("invalid bool size %d", size)
+		}
+		return size == 1, offset, nil
+	}
+
+	b, err := d.read(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset += size
+	switch typ {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes:
+		return append([]byte{}, b...), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, d.errorf("invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, d.errorf("invalid float size %d", size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if typ == typeUint16 && size > 2 || typ == typeUint32 && size > 4 || size > 8 {
+			return nil, 0, d.errorf("invalid uint size %d", size)
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, d.errorf("invalid int32 size %d", size)
+		}
+		var n uint32
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int32(n), offset, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, d.errorf("invalid uint128 size %d", size)
+		}
+		return new(big.Int).SetBytes(b), offset, nil
+	default:
+		return nil, 0, d.errorf("unsupported type %d", typ)
+	}
+}
+
+func (d *decoder) decodePointer(ctrl, offset uint) (uint, uint, error) {
+	n := (ctrl>>3)&0x3 + 1
+	b, err := d.read(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	var prefix uint
+	if n != 4 {
+		prefix = ctrl & 0x7
+	}
+	pointer := prefix
+	for _, c := range b {
+		pointer = pointer<<8 | uint(c)
+	}
+	switch n {
+	case 2:
+		pointer += 2048
+	case 3:
+		pointer += 526336
+	}
+	return pointer, offset + n, nil
+}
+
+// capacity limit initial capacity of map or array by the remaining bytes, every element takes at least one byte,
+// size is read from untrusted file and should not be allocated directly
+func (d *decoder) capacity(size, offset uint) uint {
+	if offset >= uint(len(d.buf)) {
+		return 0
+	}
+	if remaining := uint(len(d.buf)) - offset; size > remaining {
+		return remaining
+	}
+	return size
+}
+
+func (d *decoder) decodeMap(size, offset uint, depth int) (interface{}, uint, error) {
+	m := make(map[string]interface{}, d.capacity(size, offset))
+	for i := uint(0); i < size; i++ {
+		k, next, err := d.decode(offset, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, 0, d.errorf("map key at %d is not string", offset)
+		}
+		v, next, err := d.decode(next, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		m[key] = v
+		offset = next
+	}
+	return m, offset, nil
+}
+
+func (d *decoder) decodeArray(size, offset uint, depth int) (interface{}, uint, error) {
+	a := make([]interface{}, 0, d.capacity(size, offset))
+	for i := uint(0); i < size; i++ {
+		v, next, err := d.decode(offset, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		a = append(a, v)
+		offset = next
+	}
+	return a, offset, nil
+}
@@ -0,0 +1,169 @@
+package mmdb
+
+import (
+	"github.com/chainreactors/utils"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Country country record, both GeoLite2/GeoIP2 and IPinfo layouts are supported
+type Country struct {
+	ISOCode       string
+	Name          string
+	ContinentCode string
+	Continent     string
+	Network       *utils.CIDR
+}
+
+type City struct {
+	Name       string
+	Country    *Country
+	Region     string
+	PostalCode string
+	Latitude   float64
+	Longitude  float64
+	TimeZone   string
+	Network    *utils.CIDR
+}
+
+type ASN struct {
+	Number       uint
+	Organization string
+	Network      *utils.CIDR
+}
+
+// Country lookup country of ip, return nil if not found
+func (r *Reader) Country(ip *utils.IP) (*Country, error) {
+	v, network, err := r.Lookup(ip)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	country := parseCountry(toMap(v))
+	country.Network = network
+	return country, nil
+}
+
+// City lookup city of ip, return nil if not found
+func (r *Reader) City(ip *utils.IP) (*City, error) {
+	v, network, err := r.Lookup(ip)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	m := toMap(v)
+	city := &City{Country: parseCountry(m), Network: network}
+	city.Country.Network = network
+	if c, ok := m["city"].(map[string]interface{}); ok {
+		// GeoLite2 layout
+		city.Name = localName(c)
+		city.PostalCode = toString(toMap(m["postal"])["code"])
+		if subdivisions := toSlice(m["subdivisions"]); len(subdivisions) > 0 {
+			city.Region = localName(toMap(subdivisions[0]))
+		}
+		location := toMap(m["location"])
+		city.Latitude = toFloat(location["latitude"])
+		city.Longitude = toFloat(location["longitude"])
+		city.TimeZone = toString(location["time_zone"])
+	} else {
+		// IPinfo layout, coordinates may be string
+		city.Name = toString(m["city"])
+		city.Region = toString(m["region"])
+		city.PostalCode = toString(m["postal_code"])
+		city.Latitude = toFloat(m["lat"])
+		city.Longitude = toFloat(m["lng"])
+		city.TimeZone = toString(m["timezone"])
+	}
+	return city, nil
+}
+
+// ASN lookup autonomous system of ip, return nil if not found
+func (r *Reader) ASN(ip *utils.IP) (*ASN, error) {
+	v, network, err := r.Lookup(ip)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	m := toMap(v)
+	asn := &ASN{Network: network}
+	if n, ok := m["autonomous_system_number"]; ok {
+		// GeoLite2 layout
+		asn.Number = toUint(n)
+		asn.Organization = toString(m["autonomous_system_organization"])
+	} else {
+		// IPinfo layout, e.g. {"asn": "AS13335", "name": "Cloudflare, Inc."}
+		n, _ := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(toString(m["asn"])), "AS"), 10, 32)
+		asn.Number = uint(n)
+		asn.Organization = toString(m["name"])
+		if asn.Organization == "" {
+			asn.Organization = toString(m["as_name"])
+		}
+	}
+	return asn, nil
+}
+
+func parseCountry(m map[string]interface{}) *Country {
+	country := &Country{}
+	if _, ok := m["country"].(string); !ok {
+		// GeoLite2 layout, registered_country is used for anonymous proxies etc.
+		c := toMap(m["country"])
+		if len(c) == 0 {
+			c = toMap(m["registered_country"])
+		}
+		country.ISOCode = toString(c["iso_code"])
+		country.Name = localName(c)
+		continent := toMap(m["continent"])
+		country.ContinentCode = toString(continent["code"])
+		country.Continent = localName(continent)
+	} else {
+		// IPinfo layout
+		country.ISOCode = toString(m["country"])
+		country.Name = toString(m["country_name"])
+		country.ContinentCode = toString(m["continent"])
+		country.Continent = toString(m["continent_name"])
+	}
+	return country
+}
+
+// localName return english name of GeoLite2 record
+func localName(m map[string]interface{}) string {
+	return toString(toMap(m["names"])["en"])
+}
+
+func toMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func toSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func toUint(v interface{}) uint {
+	switch n := v.(type) {
+	case uint64:
+		return uint(n)
+	case int32:
+		return uint(n)
+	case *big.Int:
+		return uint(n.Uint64())
+	}
+	return 0
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,209 @@
+package mmdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/utils"
+	"io/ioutil"
+	"net"
+)
+
+var (
+	metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+	ErrInvalidDatabase = errors.New("invalid mmdb database")
+)
+
+// dataSectionSeparator 16 bytes of zero between search tree and data section
+const dataSectionSeparator = 16
+
+type Metadata struct {
+	BinaryFormatMajorVersion uint
+	BinaryFormatMinorVersion uint
+	BuildEpoch               uint
+	DatabaseType             string
+	Description              map[string]string
+	IPVersion                uint
+	Languages                []string
+	NodeCount                uint
+	RecordSize               uint
+}
+
+// Reader pure go reader of MaxMind DB format, e.g. GeoLite2 and IPinfo .mmdb files.
+// Reader is safe for concurrent lookup
+type Reader struct {
+	Metadata  Metadata
+	buffer    []byte
+	tree      []byte
+	data      *decoder
+	ipv4Start uint
+	// ipv4StartDepth depth of ipv4Start in ipv6 tree, 96 if ipv4 subtree exists
+	ipv4StartDepth int
+}
+
+// Open read the whole database file into memory
+func Open(filename string) (*Reader, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(content)
+}
+
+func FromBytes(buffer []byte) (*Reader, error) {
+	i := bytes.LastIndex(buffer, metadataStartMarker)
+	if i == -1 {
+		return nil, fmt.Errorf("%s, metadata section not found", ErrInvalidDatabase.Error())
+	}
+	meta := &decoder{buf: buffer[i+len(metadataStartMarker):]}
+	v, _, err := meta.decode(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s, metadata is not map", ErrInvalidDatabase.Error())
+	}
+
+	r := &Reader{buffer: buffer}
+	r.Metadata = Metadata{
+		BinaryFormatMajorVersion: toUint(m["binary_format_major_version"]),
+		BinaryFormatMinorVersion: toUint(m["binary_format_minor_version"]),
+		BuildEpoch:               toUint(m["build_epoch"]),
+		DatabaseType:             toString(m["database_type"]),
+		Description:              make(map[string]string),
+		IPVersion:                toUint(m["ip_version"]),
+		NodeCount:                toUint(m["node_count"]),
+		RecordSize:               toUint(m["record_size"]),
+	}
+	for k, v := range toMap(m["description"]) {
+		r.Metadata.Description[k] = toString(v)
+	}
+	for _, lang := range toSlice(m["languages"]) {
+		r.Metadata.Languages = append(r.Metadata.Languages, toString(lang))
+	}
+
+	switch r.Metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("%s, unsupported record size %d", ErrInvalidDatabase.Error(), r.Metadata.RecordSize)
+	}
+	if r.Metadata.IPVersion != 4 && r.Metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("%s, unsupported ip version %d", ErrInvalidDatabase.Error(), r.Metadata.IPVersion)
+	}
+
+	// check node count before multiplying, a crafted node_count could overflow the tree size
+	nodeSize := r.Metadata.RecordSize / 4
+	if uint(i) < dataSectionSeparator || r.Metadata.NodeCount > (uint(i)-dataSectionSeparator)/nodeSize {
+		return nil, fmt.Errorf("%s, search tree out of range", ErrInvalidDatabase.Error())
+	}
+	treeSize := nodeSize * r.Metadata.NodeCount
+	r.tree = buffer[:treeSize]
+	r.data = &decoder{buf: buffer[treeSize+dataSectionSeparator : i]}
+
+	if r.Metadata.IPVersion == 6 {
+		// ipv4 addresses are stored in ::/96 of ipv6 tree
+		node := uint(0)
+		depth := 0
+		for ; depth < 96 && node < r.Metadata.NodeCount; depth++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+		r.ipv4StartDepth = depth
+	}
+	return r, nil
+}
+
+// readNode read left(bit 0) or right(bit 1) record of node
+func (r *Reader) readNode(node uint, bit int) uint {
+	switch r.Metadata.RecordSize {
+	case 24:
+		b := r.tree[node*6+uint(bit)*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		b := r.tree[node*7:]
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		b := r.tree[node*8+uint(bit)*4:]
+		return uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3])
+	}
+}
+
+// lookup return the data offset and prefix length, offset 0 means not found
+func (r *Reader) lookup(ip net.IP) (uint, int, error) {
+	bits := 128
+	node := uint(0)
+	startDepth := 0
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		bits = 32
+		if r.Metadata.IPVersion == 6 {
+			node = r.ipv4Start
+			startDepth = r.ipv4StartDepth
+		}
+	} else if r.Metadata.IPVersion == 4 {
+		return 0, 0, fmt.Errorf("can not lookup ipv6 address %s in ipv4 database", ip.String())
+	}
+
+	nodeCount := r.Metadata.NodeCount
+	i := 0
+	for ; i < bits && node < nodeCount; i++ {
+		node = r.readNode(node, int(ip[i/8]>>uint(7-i%8))&1)
+	}
+
+	prefix := i
+	if bits == 32 && r.Metadata.IPVersion == 6 {
+		// ipv4 subtree may end before depth 96, then the record covers the whole ipv4 space
+		prefix = startDepth + i - 96
+		if prefix < 0 {
+			prefix = 0
+		}
+	}
+	if node == nodeCount {
+		return 0, prefix, nil
+	}
+	if node < nodeCount {
+		return 0, 0, fmt.Errorf("%s, search tree is deeper than address", ErrInvalidDatabase.Error())
+	}
+	offset := node - nodeCount - dataSectionSeparator
+	if node-nodeCount < dataSectionSeparator || offset >= uint(len(r.data.buf)) {
+		return 0, 0, fmt.Errorf("%s, record %d points out of data section", ErrInvalidDatabase.Error(), node)
+	}
+	// offset 0 is valid in data section, shift by one to distinguish from not found
+	return offset + 1, prefix, nil
+}
+
+// LookupNetIP return decoded record and the prefix length of network containing ip, record is nil if not found
+func (r *Reader) LookupNetIP(ip net.IP) (interface{}, int, error) {
+	offset, prefix, err := r.lookup(ip)
+	if err != nil || offset == 0 {
+		return nil, prefix, err
+	}
+	v, _, err := r.data.decode(offset-1, 0)
+	return v, prefix, err
+}
+
+// Lookup return decoded record and the network containing ip, record is nil if not found
+func (r *Reader) Lookup(ip *utils.IP) (interface{}, *utils.CIDR, error) {
+	v, prefix, err := r.LookupNetIP(ip.IP)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, ip.CIDR(prefix).FirstIP().CIDR(prefix), nil
+}
+
+// LookupCIDR lookup the network address of cidr, return error if cidr spans more than one network of database
+func (r *Reader) LookupCIDR(c *utils.CIDR) (interface{}, *utils.CIDR, error) {
+	v, network, err := r.Lookup(c.FirstIP())
+	if err != nil {
+		return nil, nil, err
+	}
+	if network.Mask > c.Mask {
+		return nil, nil, fmt.Errorf("%s spans more than one network, %s is the first", c.String(), network.String())
+	}
+	return v, network, nil
+}
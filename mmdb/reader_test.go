@@ -0,0 +1,367 @@
+package mmdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/chainreactors/utils"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"net"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+// pointer reference to the offset of data section, only used by test writer
+type pointer uint
+
+// testWriter minimal MaxMind DB writer, generate fixtures without network or real database
+type testWriter struct {
+	ipVersion  int
+	recordSize int
+	root       *testNode
+	data       bytes.Buffer
+}
+
+type testNode struct {
+	children [2]*testNode
+	leaf     bool
+	offset   int
+	id       int
+}
+
+func newTestWriter(ipVersion, recordSize int) *testWriter {
+	return &testWriter{ipVersion: ipVersion, recordSize: recordSize, root: &testNode{}}
+}
+
+// addData append value to data section and return its offset
+func (w *testWriter) addData(v interface{}) int {
+	offset := w.data.Len()
+	encodeValue(&w.data, v)
+	return offset
+}
+
+// insert cidr pointing to data offset, ipv4 cidr is stored in ::/96 of ipv6 tree
+func (w *testWriter) insert(cidr string, offset int) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	ones, _ := ipnet.Mask.Size()
+	ip := ipnet.IP
+	if w.ipVersion == 6 {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = append(make(net.IP, 12), ip4...)
+			ones += 96
+		}
+	}
+	n := w.root
+	for i := 0; i < ones; i++ {
+		b := int(ip[i/8]>>uint(7-i%8)) & 1
+		if n.children[b] == nil {
+			n.children[b] = &testNode{}
+		}
+		n = n.children[b]
+	}
+	n.leaf = true
+	n.offset = offset
+}
+
+func (w *testWriter) bytes(meta map[string]interface{}) []byte {
+	// number inner nodes in bfs order, root is 0
+	var nodes []*testNode
+	queue := []*testNode{w.root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		n.id = len(nodes)
+		nodes = append(nodes, n)
+		for _, child := range n.children {
+			if child != nil && !child.leaf {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	nodeCount := len(nodes)
+	record := func(child *testNode) uint32 {
+		switch {
+		case child == nil:
+			return uint32(nodeCount)
+		case child.leaf:
+			return uint32(nodeCount + 16 + child.offset)
+		default:
+			return uint32(child.id)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		left, right := record(n.children[0]), record(n.children[1])
+		switch w.recordSize {
+		case 24:
+			buf.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)})
+		case 28:
+			buf.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(left>>24)<<4 | byte(right>>24)&0x0f, byte(right >> 16), byte(right >> 8), byte(right)})
+		case 32:
+			binary.Write(&buf, binary.BigEndian, []uint32{left, right})
+		}
+	}
+	buf.Write(make([]byte, 16))
+	buf.Write(w.data.Bytes())
+	buf.Write(metadataStartMarker)
+
+	if _, ok := meta["node_count"]; !ok {
+		meta["node_count"] = uint32(nodeCount)
+	}
+	meta["record_size"] = uint16(w.recordSize)
+	meta["ip_version"] = uint16(w.ipVersion)
+	meta["binary_format_major_version"] = uint16(2)
+	meta["binary_format_minor_version"] = uint16(0)
+	encodeValue(&buf, meta)
+	return buf.Bytes()
+}
+
+func encodeCtrl(buf *bytes.Buffer, typ int, size int) {
+	var ctrl []byte
+	switch {
+	case size < 29:
+		ctrl = []byte{byte(size)}
+	case size < 285:
+		ctrl = []byte{29, byte(size - 29)}
+	case size < 65821:
+		ctrl = []byte{30, byte((size - 285) >> 8), byte(size - 285)}
+	default:
+		size -= 65821
+		ctrl = []byte{31, byte(size >> 16), byte(size >> 8), byte(size)}
+	}
+	if typ < 8 {
+		ctrl[0] |= byte(typ << 5)
+		buf.Write(ctrl)
+		return
+	}
+	buf.WriteByte(ctrl[0])
+	buf.WriteByte(byte(typ - 7))
+	buf.Write(ctrl[1:])
+}
+
+func encodeValue(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case pointer:
+		buf.Write([]byte{byte(typePointer<<5) | byte(v>>8)&0x7, byte(v)})
+	case string:
+		encodeCtrl(buf, typeString, len(v))
+		buf.WriteString(v)
+	case float64:
+		encodeCtrl(buf, typeDouble, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		encodeCtrl(buf, typeBool, size)
+	case uint16:
+		encodeUint(buf, typeUint16, uint64(v))
+	case uint32:
+		encodeUint(buf, typeUint32, uint64(v))
+	case uint64:
+		encodeUint(buf, typeUint64, v)
+	case []interface{}:
+		encodeCtrl(buf, typeArray, len(v))
+		for _, e := range v {
+			encodeValue(buf, e)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		encodeCtrl(buf, typeMap, len(v))
+		for _, k := range keys {
+			encodeValue(buf, k)
+			encodeValue(buf, v[k])
+		}
+	default:
+		panic("unsupported type")
+	}
+}
+
+func encodeUint(buf *bytes.Buffer, typ int, n uint64) {
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	encodeCtrl(buf, typ, len(b))
+	buf.Write(b)
+}
+
+func names(en string) map[string]interface{} {
+	return map[string]interface{}{"en": en, "zh-CN": en}
+}
+
+func buildCityDB(ipVersion, recordSize int) []byte {
+	w := newTestWriter(ipVersion, recordSize)
+	// continent shared by pointer like real GeoLite2 database
+	northAmerica := w.addData(map[string]interface{}{"code": "NA", "geoname_id": uint32(6255149), "names": names("North America")})
+	us := w.addData(map[string]interface{}{
+		"continent": pointer(northAmerica),
+		"country":   map[string]interface{}{"iso_code": "US", "names": names("United States")},
+		"city":      map[string]interface{}{"names": names("Mountain View")},
+		"location":  map[string]interface{}{"latitude": 37.386, "longitude": -122.0838, "time_zone": "America/Los_Angeles"},
+		"postal":    map[string]interface{}{"code": "94035"},
+		"subdivisions": []interface{}{
+			map[string]interface{}{"iso_code": "CA", "names": names("California")},
+		},
+	})
+	cn := w.addData(map[string]interface{}{
+		"continent": map[string]interface{}{"code": "AS", "names": names("Asia")},
+		"country":   map[string]interface{}{"iso_code": "CN", "names": names("China")},
+	})
+	w.insert("8.8.8.0/24", us)
+	w.insert("1.2.4.0/22", cn)
+	if ipVersion == 6 {
+		w.insert("2001:4860::/32", us)
+	}
+	return w.bytes(map[string]interface{}{
+		"database_type": "GeoLite2-City",
+		"languages":     []interface{}{"en", "zh-CN"},
+		"description":   map[string]interface{}{"en": "test database"},
+		"build_epoch":   uint64(1700000000),
+	})
+}
+
+func TestReader_City(t *testing.T) {
+	for _, ipVersion := range []int{4, 6} {
+		for _, recordSize := range []int{24, 28, 32} {
+			r, err := FromBytes(buildCityDB(ipVersion, recordSize))
+			if !assert.NoError(t, err) {
+				continue
+			}
+			assert.Equal(t, "GeoLite2-City", r.Metadata.DatabaseType)
+			assert.Equal(t, uint(recordSize), r.Metadata.RecordSize)
+			assert.Equal(t, []string{"en", "zh-CN"}, r.Metadata.Languages)
+			assert.Equal(t, "test database", r.Metadata.Description["en"])
+			assert.Equal(t, uint(1700000000), r.Metadata.BuildEpoch)
+
+			city, err := r.City(utils.ParseIP("8.8.8.8"))
+			assert.NoError(t, err)
+			assert.Equal(t, "Mountain View", city.Name)
+			assert.Equal(t, "California", city.Region)
+			assert.Equal(t, "94035", city.PostalCode)
+			assert.Equal(t, "America/Los_Angeles", city.TimeZone)
+			assert.Equal(t, 37.386, city.Latitude)
+			assert.Equal(t, "US", city.Country.ISOCode)
+			assert.Equal(t, "North America", city.Country.Continent)
+			assert.Equal(t, "8.8.8.0/24", city.Network.String())
+
+			country, err := r.Country(utils.ParseIP("1.2.5.6"))
+			assert.NoError(t, err)
+			assert.Equal(t, "CN", country.ISOCode)
+			assert.Equal(t, "China", country.Name)
+			assert.Equal(t, "AS", country.ContinentCode)
+			assert.Equal(t, "1.2.4.0/22", country.Network.String())
+
+			country, err = r.Country(utils.ParseIP("9.9.9.9"))
+			assert.NoError(t, err)
+			assert.Nil(t, country)
+
+			v, network, err := r.LookupCIDR(utils.ParseCIDR("1.2.6.0/24"))
+			assert.NoError(t, err)
+			assert.NotNil(t, v)
+			assert.Equal(t, "1.2.4.0/22", network.String())
+			_, _, err = r.LookupCIDR(utils.ParseCIDR("8.8.0.0/16"))
+			assert.Error(t, err)
+
+			if ipVersion == 6 {
+				country, err = r.Country(utils.ParseIP("2001:4860:4860::8888"))
+				assert.NoError(t, err)
+				assert.Equal(t, "US", country.ISOCode)
+				assert.Equal(t, "2001:4860::/32", country.Network.String())
+			} else {
+				_, err = r.Country(utils.ParseIP("2001:4860:4860::8888"))
+				assert.Error(t, err)
+			}
+		}
+	}
+}
+
+func TestReader_IPinfo(t *testing.T) {
+	w := newTestWriter(6, 24)
+	w.insert("1.1.1.0/24", w.addData(map[string]interface{}{
+		"asn": "AS13335", "name": "Cloudflare, Inc.", "domain": "cloudflare.com",
+		"country": "AU", "country_name": "Australia", "continent": "OC", "continent_name": "Oceania",
+	}))
+	w.insert("2606:4700::/32", w.addData(map[string]interface{}{
+		"asn": "AS13335", "name": "Cloudflare, Inc.", "country": "US", "city": "San Francisco", "lat": "37.7621", "lng": "-122.3971",
+	}))
+	r, err := FromBytes(w.bytes(map[string]interface{}{"database_type": "ipinfo standard_asn"}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	asn, err := r.ASN(utils.ParseIP("1.1.1.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(13335), asn.Number)
+	assert.Equal(t, "Cloudflare, Inc.", asn.Organization)
+	assert.Equal(t, "1.1.1.0/24", asn.Network.String())
+
+	country, err := r.Country(utils.ParseIP("1.1.1.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "AU", country.ISOCode)
+	assert.Equal(t, "Oceania", country.Continent)
+
+	city, err := r.City(utils.ParseIP("2606:4700::1111"))
+	assert.NoError(t, err)
+	assert.Equal(t, "San Francisco", city.Name)
+	assert.Equal(t, 37.7621, city.Latitude)
+	assert.Equal(t, "US", city.Country.ISOCode)
+}
+
+func TestReader_GeoLiteASN(t *testing.T) {
+	w := newTestWriter(4, 28)
+	w.insert("8.8.8.0/24", w.addData(map[string]interface{}{
+		"autonomous_system_number":       uint32(15169),
+		"autonomous_system_organization": "GOOGLE",
+	}))
+	r, err := FromBytes(w.bytes(map[string]interface{}{"database_type": "GeoLite2-ASN"}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	asn, err := r.ASN(utils.ParseIP("8.8.8.8"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(15169), asn.Number)
+	assert.Equal(t, "GOOGLE", asn.Organization)
+}
+
+func TestReader_Invalid(t *testing.T) {
+	_, err := FromBytes([]byte("not a database"))
+	assert.Error(t, err)
+
+	db := buildCityDB(6, 24)
+	_, err = FromBytes(db[len(db)-60:])
+	assert.Error(t, err)
+
+	// 6 * 3074457345618258603 overflows to 2 bytes of search tree
+	w := newTestWriter(6, 24)
+	w.insert("8.8.8.0/24", w.addData("us"))
+	_, err = FromBytes(w.bytes(map[string]interface{}{"node_count": uint64(3074457345618258603)}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidDatabase.Error())
+}
+
+func TestDecoder_HugeSize(t *testing.T) {
+	// array and map of 16M elements declared by a few bytes
+	for _, buf := range [][]byte{
+		{0x1f, typeArray - 7, 0xff, 0xff, 0xff, 0x41, 'a'},
+		{0xff, 0xff, 0xff, 0xff, 0x41, 'a'},
+	} {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, _, err := (&decoder{buf: buf}).decode(0, 0)
+		runtime.ReadMemStats(&after)
+		assert.Error(t, err)
+		assert.True(t, after.TotalAlloc-before.TotalAlloc < 1<<20, "allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+	}
+}
@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/twmb/murmur3"
+	"math/big"
+	"sort"
+)
+
+const feistelRounds = 4
+
+// targetSpace flat index space of cidrs * ports, index = ipIndex * len(ports) + portIndex
+type targetSpace struct {
+	firsts  []*IP
+	offsets []*big.Int
+	ports   []string
+	count   *big.Int
+}
+
+func newTargetSpace(cs CIDRs, ports []string) *targetSpace {
+	space := &targetSpace{ports: ports}
+	sum := new(big.Int)
+	for _, c := range cs {
+		space.firsts = append(space.firsts, c.FirstIP())
+		space.offsets = append(space.offsets, new(big.Int).Set(sum))
+		sum.Add(sum, c.Count())
+	}
+	space.count = sum.Mul(sum, big.NewInt(int64(space.portCount())))
+	return space
+}
+
+func (s *targetSpace) portCount() int {
+	if len(s.ports) == 0 {
+		return 1
+	}
+	return len(s.ports)
+}
+
+// at return the target of index, index must be less than count
+func (s *targetSpace) at(index *big.Int) *Addr {
+	ipIndex, portIndex := new(big.Int).DivMod(index, big.NewInt(int64(s.portCount())), new(big.Int))
+	i := sort.Search(len(s.offsets), func(i int) bool {
+		return s.offsets[i].Cmp(ipIndex) > 0
+	}) - 1
+
+	// index is less than count, ip never overflows
+	ip, _ := s.firsts[i].Add(ipIndex.Sub(ipIndex, s.offsets[i]))
+	if len(s.ports) == 0 {
+		return &Addr{IP: ip}
+	}
+	return &Addr{IP: ip, Port: s.ports[portIndex.Int64()]}
+}
+
+// feistel a balanced feistel network over [0, domain), values out of domain are cycle walked
+type feistel struct {
+	domain *big.Int
+	half   uint
+	mask   *big.Int
+	keys   [feistelRounds][2]uint64
+}
+
+func newFeistel(domain *big.Int, seed int64) *feistel {
+	bits := uint(new(big.Int).Sub(domain, bigOne).BitLen())
+	half := (bits + 1) / 2
+	if half == 0 {
+		half = 1
+	}
+	f := &feistel{
+		domain: domain,
+		half:   half,
+		mask:   new(big.Int).Sub(new(big.Int).Lsh(bigOne, half), bigOne),
+	}
+	state := uint64(seed)
+	for i := range f.keys {
+		f.keys[i] = [2]uint64{splitmix64(&state), splitmix64(&state)}
+	}
+	return f
+}
+
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (f *feistel) round(i int, r *big.Int) *big.Int {
+	h1, h2 := murmur3.SeedSum128(f.keys[i][0], f.keys[i][1], r.Bytes())
+	v := new(big.Int).SetUint64(h1)
+	if f.half > 64 {
+		v.Lsh(v, 64).Or(v, new(big.Int).SetUint64(h2))
+	}
+	return v.And(v, f.mask)
+}
+
+func (f *feistel) encrypt(x *big.Int) *big.Int {
+	l := new(big.Int).Rsh(x, f.half)
+	r := new(big.Int).And(x, f.mask)
+	for i := range f.keys {
+		l, r = r, l.Xor(l, f.round(i, r))
+	}
+	return l.Lsh(l, f.half).Or(l, r)
+}
+
+// permute map x in [0, domain) to another unique value in [0, domain)
+func (f *feistel) permute(x *big.Int) *big.Int {
+	y := f.encrypt(x)
+	for y.Cmp(f.domain) >= 0 {
+		y = f.encrypt(y)
+	}
+	return y
+}
+
+// Checkpoint the compact state of a Permutation, can be serialized to json and resumed later
+type Checkpoint struct {
+	Seed     int64    `json:"seed" yaml:"seed"`
+	Shard    int      `json:"shard" yaml:"shard"`
+	Shards   int      `json:"shards" yaml:"shards"`
+	Position *big.Int `json:"position" yaml:"position"`
+}
+
+// Permutation visit every target of cidrs * ports exactly once, in a random looking order decided by seed.
+// only the position is stored, so memory is constant no matter how many targets.
+//
+// the walk can be split into shards, shard i of n visits positions i, i+n, i+2n ...,
+// so shards with the same seed never overlap and together cover every target
+type Permutation struct {
+	space   *targetSpace
+	feistel *feistel
+	seed    int64
+	shard   int
+	shards  int
+	pos     *big.Int
+}
+
+// NewPermutation create permutation of cidrs * ports, Addr.Port will be empty if ports is empty
+func NewPermutation(cs CIDRs, ports []string, seed int64) *Permutation {
+	space := newTargetSpace(cs, ports)
+	return &Permutation{
+		space:   space,
+		feistel: newFeistel(space.count, seed),
+		seed:    seed,
+		shards:  1,
+		pos:     new(big.Int),
+	}
+}
+
+// Permutation create permutation of all ips in cidrs
+func (cs CIDRs) Permutation(seed int64) *Permutation {
+	return NewPermutation(cs, nil, seed)
+}
+
+// Permutation create permutation of ips * ports
+func (as AddrsGenerator) Permutation(seed int64) *Permutation {
+	cs := make(CIDRs, len(as.IPs))
+	for i, ip := range as.IPs {
+		cs[i] = ip.CIDR(ip.Len() * 8)
+	}
+	return NewPermutation(cs, as.Ports, seed)
+}
+
+// Count return the count of all targets, not only this shard
+func (p *Permutation) Count() *big.Int {
+	return new(big.Int).Set(p.space.count)
+}
+
+// ShardCount return the count of targets in this shard
+func (p *Permutation) ShardCount() *big.Int {
+	shard := big.NewInt(int64(p.shard))
+	if p.space.count.Cmp(shard) <= 0 {
+		return new(big.Int)
+	}
+	shards := big.NewInt(int64(p.shards))
+	count := new(big.Int).Sub(p.space.count, shard)
+	count.Add(count, shards).Sub(count, bigOne)
+	return count.Div(count, shards)
+}
+
+func (p *Permutation) Seed() int64 {
+	return p.seed
+}
+
+// SetShard make the permutation only visit shard of shards, shard starts from 0, position will be reset
+func (p *Permutation) SetShard(shard, shards int) error {
+	if shards < 1 || shard < 0 || shard >= shards {
+		return fmt.Errorf("invalid shard %d of %d", shard, shards)
+	}
+	p.shard = shard
+	p.shards = shards
+	p.Reset()
+	return nil
+}
+
+func (p *Permutation) Next() (*Addr, bool) {
+	if p.pos.Cmp(p.space.count) >= 0 {
+		return nil, false
+	}
+	index := p.feistel.permute(p.pos)
+	p.pos.Add(p.pos, big.NewInt(int64(p.shards)))
+	return p.space.at(index), true
+}
+
+func (p *Permutation) Reset() {
+	p.pos = big.NewInt(int64(p.shard))
+}
+
+// Checkpoint return current state, targets returned by Next before will not be replayed after Resume
+func (p *Permutation) Checkpoint() *Checkpoint {
+	return &Checkpoint{
+		Seed:     p.seed,
+		Shard:    p.shard,
+		Shards:   p.shards,
+		Position: new(big.Int).Set(p.pos),
+	}
+}
+
+// Resume restore the state from checkpoint, the permutation must be created with the same cidrs and ports
+func (p *Permutation) Resume(cp *Checkpoint) error {
+	if cp.Position == nil || cp.Position.Sign() < 0 {
+		return fmt.Errorf("invalid checkpoint position")
+	}
+	if err := p.SetShard(cp.Shard, cp.Shards); err != nil {
+		return err
+	}
+	if new(big.Int).Mod(cp.Position, big.NewInt(int64(cp.Shards))).Int64() != int64(cp.Shard) {
+		return fmt.Errorf("checkpoint position %s not belongs to shard %d of %d", cp.Position.String(), cp.Shard, cp.Shards)
+	}
+	if cp.Seed != p.seed {
+		p.seed = cp.Seed
+		p.feistel = newFeistel(p.space.count, cp.Seed)
+	}
+	p.pos = new(big.Int).Set(cp.Position)
+	return nil
+}
+
+func (p *Permutation) Range() chan *Addr {
+	return p.RangeWithContext(context.Background())
+}
+
+func (p *Permutation) RangeWithContext(ctx context.Context) chan *Addr {
+	return RangeAddrIterator(ctx, p)
+}
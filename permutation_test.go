@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPermutation(t *testing.T) {
+	cs := ParseCIDRs([]string{"10.0.0.0/24", "192.168.1.0/30", "2001:db8::/126"})
+	ports := []string{"80", "443", "8080"}
+	p := NewPermutation(cs, ports, 42)
+	assert.Equal(t, "792", p.Count().String())
+
+	seen := make(map[string]bool)
+	var order []string
+	for addr, ok := p.Next(); ok; addr, ok = p.Next() {
+		assert.False(t, seen[addr.String()], addr.String())
+		seen[addr.String()] = true
+		order = append(order, addr.String())
+	}
+	assert.Equal(t, 792, len(seen))
+	assert.True(t, seen["192.168.1.3:8080"])
+	assert.True(t, seen["[2001:db8::3]:443"])
+	assert.NotEqual(t, "10.0.0.0:80", order[0])
+
+	p.Reset()
+	addr, _ := p.Next()
+	assert.Equal(t, order[0], addr.String(), "same seed should be reproducible")
+
+	other, _ := NewPermutation(cs, ports, 43).Next()
+	q := NewPermutation(cs, ports, 43)
+	for i := 0; i < 10; i++ {
+		a1, _ := q.Next()
+		if a1.String() != order[i] {
+			return
+		}
+	}
+	t.Errorf("different seed got the same order, first %s", other.String())
+}
+
+func TestPermutation_Huge(t *testing.T) {
+	p := ParseCIDRs([]string{"2001:db8::/32"}).Permutation(1)
+	assert.Equal(t, "79228162514264337593543950336", p.Count().String())
+	c := ParseCIDR("2001:db8::/32")
+	for i := 0; i < 100; i++ {
+		addr, ok := p.Next()
+		assert.True(t, ok)
+		assert.True(t, c.ContainsIP(addr.IP))
+	}
+}
+
+func TestPermutation_Shard(t *testing.T) {
+	as := NewAddrsWithPorts([]string{"10.0.0.0-10.0.0.99"}, []string{"22", "80", "443"})
+	seen := make(map[string]int)
+	var total int64
+	for i := 0; i < 3; i++ {
+		p := as.Permutation(7)
+		assert.NoError(t, p.SetShard(i, 3))
+		total += p.ShardCount().Int64()
+		for addr, ok := p.Next(); ok; addr, ok = p.Next() {
+			seen[addr.String()]++
+		}
+	}
+	assert.Equal(t, int64(300), total)
+	assert.Equal(t, 300, len(seen))
+	for addr, n := range seen {
+		assert.Equal(t, 1, n, addr)
+	}
+	assert.Error(t, as.Permutation(7).SetShard(3, 3))
+}
+
+func TestPermutation_Checkpoint(t *testing.T) {
+	cs := ParseCIDRs([]string{"172.16.0.0/22"})
+	p := NewPermutation(cs, []string{"80", "443"}, 99)
+	assert.NoError(t, p.SetShard(1, 4))
+	for i := 0; i < 100; i++ {
+		p.Next()
+	}
+	data, err := json.Marshal(p.Checkpoint())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"seed":99,"shard":1,"shards":4,"position":401}`, string(data))
+
+	var rest []string
+	for addr, ok := p.Next(); ok; addr, ok = p.Next() {
+		rest = append(rest, addr.String())
+	}
+
+	var cp Checkpoint
+	assert.NoError(t, json.Unmarshal(data, &cp))
+	resumed := NewPermutation(cs, []string{"80", "443"}, 0)
+	assert.NoError(t, resumed.Resume(&cp))
+	var resumedRest []string
+	for addr, ok := resumed.Next(); ok; addr, ok = resumed.Next() {
+		resumedRest = append(resumedRest, addr.String())
+	}
+	assert.Equal(t, rest, resumedRest)
+	assert.Equal(t, 2048/4-100, len(rest))
+}
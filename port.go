@@ -48,6 +48,7 @@ func NewPortPreset(conf []*PortConfig) *PortPreset {
 		TagMap:  make(PortMapper),
 	}
 	for _, v := range conf {
+		preset.configs = append(preset.configs, v)
 		ports := expandPorts(v.Ports)
 		preset.NameMap.Append(v.Name, ports...)
 		for _, t := range v.Tags {
@@ -64,6 +65,12 @@ type PortPreset struct {
 	NameMap PortMapper
 	PortMap PortMapper
 	TagMap  PortMapper
+	configs []*PortConfig
+}
+
+// Configs return the port configs of preset
+func (preset PortPreset) Configs() []*PortConfig {
+	return preset.configs
 }
 
 // 端口预设
@@ -153,18 +160,105 @@ func expandPorts(ports []string) []string {
 	return tmpports
 }
 
-// 将string格式的port range 转为单个port组成的slice
+// 将string格式的port range 转为单个port组成的slice, 错误的port range会被跳过
 func expandPort(port string) []string {
-	var tmpports []string
 	if strings.Contains(port, "-") {
-		sf := strings.Split(port, "-")
-		start, _ := strconv.Atoi(sf[0])
-		fin, _ := strconv.Atoi(sf[1])
-		for port := start; port <= fin; port++ {
-			tmpports = append(tmpports, strconv.Itoa(port))
+		ports, err := expandPortE(port)
+		if err != nil {
+			return nil
 		}
-	} else {
-		tmpports = append(tmpports, port)
+		return ports
 	}
-	return tmpports
+	return []string{port}
+}
+
+func parsePortNumber(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 || n > 65535 {
+		return 0, ErrPortRange
+	}
+	return n, nil
+}
+
+// expandPortE expand single port or port range, e.g. 80, 1-1024, -1024, 1024-
+func expandPortE(port string) ([]string, error) {
+	port = strings.TrimSpace(port)
+	if !strings.Contains(port, "-") {
+		n, err := parsePortNumber(port)
+		if err != nil {
+			return nil, err
+		}
+		return []string{strconv.Itoa(n)}, nil
+	}
+
+	sf := strings.SplitN(port, "-", 2)
+	if sf[0] == "" {
+		sf[0] = "1"
+	}
+	if sf[1] == "" {
+		sf[1] = "65535"
+	}
+	start, err := parsePortNumber(sf[0])
+	if err != nil {
+		return nil, err
+	}
+	fin, err := parsePortNumber(sf[1])
+	if err != nil || start > fin {
+		return nil, ErrPortRange
+	}
+	ports := make([]string, 0, fin-start+1)
+	for p := start; p <= fin; p++ {
+		ports = append(ports, strconv.Itoa(p))
+	}
+	return ports, nil
+}
+
+// ParsePortsE like ParsePortsString, port names are resolved by PrePort if set, unknown name or invalid port return ErrPortRange
+func ParsePortsE(s string) ([]string, error) {
+	preset := PrePort
+	if preset == nil {
+		preset = NewPortPreset(nil)
+	}
+	return preset.ParsePortStringE(s)
+}
+
+// isPortName name is all, a name or a tag of preset
+func (preset PortPreset) isPortName(name string) bool {
+	return name == "all" || preset.NameMap.Get(name) != nil || preset.TagMap.Get(name) != nil
+}
+
+// ParsePortStringE like ParsePortString, but return ErrPortRange with the offset of wrong port
+func (preset PortPreset) ParsePortStringE(s string) ([]string, error) {
+	var ports, excludes []string
+	pos := 0
+	for _, field := range strings.Split(s, ",") {
+		start := pos
+		pos += len(field) + 1
+		name := strings.TrimSpace(field)
+		if name == "" {
+			continue
+		}
+		exclude := len(name) > 1 && name[0] == '-'
+		if exclude {
+			name = name[1:]
+		}
+
+		var expanded []string
+		if preset.isPortName(name) {
+			// ports of preset are passed through like ParsePortString, e.g. icmp, winrm
+			expanded = expandPorts(preset.ChoicePort(name))
+		} else {
+			ps, err := expandPortE(name)
+			if err != nil {
+				return nil, newParseError(s, start+strings.Index(field, name), ErrPortRange)
+			}
+			expanded = ps
+		}
+		if exclude {
+			excludes = append(excludes, expanded...)
+		} else {
+			ports = append(ports, expanded...)
+		}
+	}
+	return removeExcludedPorts(iutils.StringsUnique(ports), excludes), nil
 }
@@ -64,3 +64,30 @@ func TestParsePortString(t *testing.T) {
 		assert.ElementsMatch(t, tc.expected, actual)
 	}
 }
+
+func TestParsePortStringE_Preset(t *testing.T) {
+	var ports []*PortConfig
+	err := yaml.Unmarshal([]byte(content), &ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preset := NewPortPreset(ports)
+
+	// ParsePortStringE must accept every name and tag like ParsePortString, including non-numeric ports
+	var keys []string
+	for k := range preset.NameMap {
+		keys = append(keys, k)
+	}
+	for k := range preset.TagMap {
+		keys = append(keys, k)
+	}
+	keys = append(keys, "all", "top2,-win,-84,-1-10000")
+	for _, k := range keys {
+		actual, err := preset.ParsePortStringE(k)
+		assert.NoError(t, err, k)
+		assert.ElementsMatch(t, preset.ParsePortString(k), actual, k)
+	}
+
+	_, err = preset.ParsePortStringE("win,icmp")
+	assert.Error(t, err)
+}
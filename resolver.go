@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver resolve hostname to all of its A/AAAA records
+type Resolver interface {
+	LookupIPs(ctx context.Context, host string) (IPs, error)
+}
+
+var (
+	// DefaultResolver used by ParseIP, ParseCIDR and ParseHostToIP when no resolver given,
+	// set it to NoDNS to disable dns resolution globally
+	DefaultResolver Resolver = NewNetResolver(5 * time.Second)
+
+	// NoDNS strict mode, never resolve hostname
+	NoDNS Resolver = noDNSResolver{}
+
+	errNoDNS = errors.New("dns resolution is disabled")
+)
+
+func getResolver(resolver []Resolver) Resolver {
+	if len(resolver) > 0 && resolver[0] != nil {
+		return resolver[0]
+	}
+	return DefaultResolver
+}
+
+// newResolvedIP normalize resolved ip and keep the hostname
+func newResolvedIP(ip net.IP, host string) *IP {
+	i := NewIP(ip)
+	i.Host = host
+	return i
+}
+
+type noDNSResolver struct{}
+
+func (noDNSResolver) LookupIPs(ctx context.Context, host string) (IPs, error) {
+	return nil, errNoDNS
+}
+
+// NetResolver resolve by net.Resolver, every lookup is limited by Timeout
+type NetResolver struct {
+	Resolver *net.Resolver
+	Timeout  time.Duration
+}
+
+func NewNetResolver(timeout time.Duration) *NetResolver {
+	return &NetResolver{Resolver: net.DefaultResolver, Timeout: timeout}
+}
+
+func (r *NetResolver) LookupIPs(ctx context.Context, host string) (IPs, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make(IPs, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, newResolvedIP(addr.IP, host))
+	}
+	return ips, nil
+}
+
+// StaticResolver resolve from static map, e.g. hosts file, hostname is case insensitive
+type StaticResolver struct {
+	hosts map[string][]net.IP
+}
+
+// NewStaticResolver build resolver from map of hostname to ip strings
+func NewStaticResolver(hosts map[string][]string) *StaticResolver {
+	r := &StaticResolver{hosts: make(map[string][]net.IP)}
+	for host, ips := range hosts {
+		for _, ip := range ips {
+			r.Add(host, ip)
+		}
+	}
+	return r
+}
+
+// LoadHostsFile build resolver from hosts file, e.g. /etc/hosts
+func LoadHostsFile(filename string) (*StaticResolver, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseHosts(f)
+}
+
+// ParseHosts parse hosts file format, invalid lines are skipped
+func ParseHosts(reader io.Reader) (*StaticResolver, error) {
+	r := &StaticResolver{hosts: make(map[string][]net.IP)}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, host := range fields[1:] {
+			r.Add(host, fields[0])
+		}
+	}
+	return r, scanner.Err()
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// Add add a record of host, return false if ip is invalid
+func (r *StaticResolver) Add(host, ip string) bool {
+	i := net.ParseIP(ip)
+	if i == nil {
+		return false
+	}
+	host = normalizeHost(host)
+	r.hosts[host] = append(r.hosts[host], i)
+	return true
+}
+
+func (r *StaticResolver) LookupIPs(ctx context.Context, host string) (IPs, error) {
+	records, ok := r.hosts[normalizeHost(host)]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in static hosts", host)
+	}
+	ips := make(IPs, len(records))
+	for i, ip := range records {
+		ips[i] = newResolvedIP(ip, host)
+	}
+	return ips, nil
+}
+
+// ChainResolver try resolvers in order, return the first success, e.g. hosts file then dns
+type ChainResolver []Resolver
+
+func (rs ChainResolver) LookupIPs(ctx context.Context, host string) (IPs, error) {
+	err := fmt.Errorf("no resolver for %s", host)
+	for _, r := range rs {
+		var ips IPs
+		ips, err = r.LookupIPs(ctx, host)
+		if err == nil && len(ips) > 0 {
+			return ips, nil
+		}
+	}
+	return nil, err
+}
+
+type cacheEntry struct {
+	ips    IPs
+	expire time.Time
+}
+
+// CacheResolver cache successful lookups of Resolver for TTL, safe for concurrent use
+type CacheResolver struct {
+	Resolver Resolver
+	TTL      time.Duration
+	mu       sync.Mutex
+	cache    map[string]*cacheEntry
+}
+
+func NewCacheResolver(resolver Resolver, ttl time.Duration) *CacheResolver {
+	return &CacheResolver{Resolver: resolver, TTL: ttl, cache: make(map[string]*cacheEntry)}
+}
+
+func (r *CacheResolver) LookupIPs(ctx context.Context, host string) (IPs, error) {
+	key := normalizeHost(host)
+	r.mu.Lock()
+	if e, ok := r.cache[key]; ok {
+		if time.Now().Before(e.expire) {
+			r.mu.Unlock()
+			return copyIPs(e.ips), nil
+		}
+		delete(r.cache, key)
+	}
+	r.mu.Unlock()
+
+	ips, err := r.Resolver.LookupIPs(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.cache[key] = &cacheEntry{ips: copyIPs(ips), expire: time.Now().Add(r.TTL)}
+	r.mu.Unlock()
+	return ips, nil
+}
+
+// Purge drop all cached records
+func (r *CacheResolver) Purge() {
+	r.mu.Lock()
+	r.cache = make(map[string]*cacheEntry)
+	r.mu.Unlock()
+}
+
+func copyIPs(ips IPs) IPs {
+	c := make(IPs, len(ips))
+	for i, ip := range ips {
+		c[i] = ip.Copy()
+	}
+	return c
+}
+
+// ParseHostToIPs resolve host to every A/AAAA record, hostname is kept in IP.Host
+func ParseHostToIPs(target string, resolver ...Resolver) (IPs, error) {
+	return ParseHostToIPsWithContext(context.Background(), target, resolver...)
+}
+
+func ParseHostToIPsWithContext(ctx context.Context, target string, resolver ...Resolver) (IPs, error) {
+	host := ParseHost(target)
+	ips, err := getResolver(resolver).LookupIPs(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve domain name %s, %s", target, err.Error())
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("not found Ip address")
+	}
+	return ips, nil
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+type countResolver struct {
+	Resolver
+	count int
+}
+
+func (r *countResolver) LookupIPs(ctx context.Context, host string) (IPs, error) {
+	r.count++
+	return r.Resolver.LookupIPs(ctx, host)
+}
+
+func TestResolver(t *testing.T) {
+	hosts, err := ParseHosts(strings.NewReader(`
+# comment
+127.0.0.1 localhost
+10.0.0.1  web.corp web
+10.0.0.2  web.corp # second record
+2001:db8::1 web.corp
+invalid   skipped
+`))
+	assert.NoError(t, err)
+
+	ip := ParseIP("WEB.corp.", hosts)
+	assert.Equal(t, "10.0.0.1", ip.String())
+	assert.Equal(t, "WEB.corp.", ip.Host)
+	assert.Nil(t, ParseIP("skipped", hosts))
+	assert.Nil(t, ParseIP("web.corp", NoDNS))
+	assert.Equal(t, "1.2.3.4", ParseIP("1.2.3.4", NoDNS).String())
+
+	ips, err := ParseHostToIPs("http://web.corp/index", hosts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "2001:db8::1"}, ips.Strings())
+
+	assert.Equal(t, "10.0.0.1/24", ParseCIDR("web/24", hosts).String())
+	assert.Nil(t, ParseCIDR("web/24", NoDNS))
+
+	addrs := NewAddrsWithDefaultPort([]string{"web.corp", "web:8080", "1.1.1.1:53", "unknown"}, "80", hosts)
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.1:8080", "1.1.1.1:53"}, addrStrings(addrs))
+	addrs = NewAddrsFanOut([]string{"web.corp:443", "1.1.1.1", "unknown"}, "80", hosts)
+	assert.Equal(t, []string{"10.0.0.1:443", "10.0.0.2:443", "[2001:db8::1]:443", "1.1.1.1:80"}, addrStrings(addrs))
+
+	chain := ChainResolver{NewStaticResolver(map[string][]string{"a.test": {"192.0.2.1"}}), hosts}
+	assert.Equal(t, "192.0.2.1", ParseIP("a.test", chain).String())
+	assert.Equal(t, "127.0.0.1", ParseIP("localhost", chain).String())
+	assert.Nil(t, ParseIP("b.test", chain))
+}
+
+func TestCacheResolver(t *testing.T) {
+	counter := &countResolver{Resolver: NewStaticResolver(map[string][]string{"a.test": {"192.0.2.1"}})}
+	cache := NewCacheResolver(counter, time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "192.0.2.1", ParseIP("a.test", cache).String())
+	}
+	assert.Nil(t, ParseIP("b.test", cache))
+	assert.Equal(t, 2, counter.count)
+
+	cache.Purge()
+	ParseIP("a.test", cache)
+	assert.Equal(t, 3, counter.count)
+
+	cache.TTL = 0
+	cache.Purge()
+	ParseIP("a.test", cache)
+	ParseIP("a.test", cache)
+	assert.Equal(t, 5, counter.count)
+}
+
+func addrStrings(addrs Addrs) []string {
+	var ss []string
+	for _, a := range addrs {
+		ss = append(ss, a.String())
+	}
+	return ss
+}
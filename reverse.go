@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	ipv4ReverseSuffix = "in-addr.arpa"
+	ipv6ReverseSuffix = "ip6.arpa"
+	hexDigits         = "0123456789abcdef"
+)
+
+// ReverseName return PTR name of ip, e.g. 4.3.2.1.in-addr.arpa
+func (ip *IP) ReverseName() string {
+	if ip.Ver == IPV4 {
+		return reverseOctets(ipBytes(ip), 4)
+	}
+	return reverseNibbles(ipBytes(ip), 32)
+}
+
+// reverseOctets reverse the first n octets of ipv4
+func reverseOctets(ip net.IP, n int) string {
+	labels := make([]string, 0, n+1)
+	for i := n - 1; i >= 0; i-- {
+		labels = append(labels, strconv.Itoa(int(ip[i])))
+	}
+	return strings.Join(append(labels, ipv4ReverseSuffix), ".")
+}
+
+// reverseNibbles reverse the first n nibbles of ipv6
+func reverseNibbles(ip net.IP, n int) string {
+	labels := make([]string, 0, n+1)
+	for i := n - 1; i >= 0; i-- {
+		b := ip[i/2]
+		if i%2 == 0 {
+			b >>= 4
+		}
+		labels = append(labels, string(hexDigits[b&0xf]))
+	}
+	return strings.Join(append(labels, ipv6ReverseSuffix), ".")
+}
+
+// ReverseZones return the minimal reverse zones covering cidr.
+// zones are split at octet boundaries for ipv4 and nibble boundaries for ipv6,
+// ipv4 prefix longer than /24 use RFC 2317 classless name, e.g. 64/26.2.0.192.in-addr.arpa
+func (c *CIDR) ReverseZones() []string {
+	first := c.FirstIP()
+	if c.Ver == IPV4 {
+		if c.Mask >= 32 {
+			return []string{first.ReverseName()}
+		}
+		if c.Mask > 24 {
+			ip := ipBytes(first)
+			return []string{fmt.Sprintf("%d/%d.%s", ip[3], c.Mask, reverseOctets(ip, 3))}
+		}
+	}
+
+	unit := 4
+	if c.Ver == IPV4 {
+		unit = 8
+	}
+	zoneMask := (c.Mask + unit - 1) / unit * unit
+	subs, err := first.CIDR(c.Mask).Split(zoneMask)
+	if err != nil {
+		return nil
+	}
+	zones := make([]string, len(subs))
+	for i, sub := range subs {
+		if c.Ver == IPV4 {
+			zones[i] = reverseOctets(ipBytes(sub.IP), zoneMask/8)
+		} else {
+			zones[i] = reverseNibbles(ipBytes(sub.IP), zoneMask/4)
+		}
+	}
+	return zones
+}
+
+// ParseReverseName parse PTR name back to ip, name must contain every octet or nibble
+func ParseReverseName(name string) (*IP, error) {
+	c, err := ParseReverseZone(name)
+	if err != nil {
+		return nil, err
+	}
+	if c.Mask != c.Len()*8 {
+		return nil, fmt.Errorf("%s is a reverse zone, not a full name", name)
+	}
+	return c.IP, nil
+}
+
+// ParseReverseZone parse reverse zone or PTR name to cidr, RFC 2317 classless name is supported
+func ParseReverseZone(zone string) (*CIDR, error) {
+	name := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(zone), "."))
+	switch {
+	case name == ipv4ReverseSuffix || strings.HasSuffix(name, "."+ipv4ReverseSuffix):
+		return parseIPv4ReverseZone(zone, strings.TrimSuffix(name, ipv4ReverseSuffix))
+	case name == ipv6ReverseSuffix || strings.HasSuffix(name, "."+ipv6ReverseSuffix):
+		return parseIPv6ReverseZone(zone, strings.TrimSuffix(name, ipv6ReverseSuffix))
+	}
+	return nil, fmt.Errorf("%s is not a reverse name", zone)
+}
+
+func splitReverseLabels(prefix string) []string {
+	prefix = strings.TrimSuffix(prefix, ".")
+	if prefix == "" {
+		return nil
+	}
+	return strings.Split(prefix, ".")
+}
+
+func parseIPv4ReverseZone(zone, prefix string) (*CIDR, error) {
+	labels := splitReverseLabels(prefix)
+	if len(labels) > 4 {
+		return nil, fmt.Errorf("%s has too many labels", zone)
+	}
+
+	mask := len(labels) * 8
+	ip := make(net.IP, net.IPv4len)
+	for i, label := range labels {
+		pos := len(labels) - 1 - i
+		if i == 0 && len(labels) == 4 && strings.Contains(label, "/") {
+			// RFC 2317, e.g. 64/26
+			parts := strings.SplitN(label, "/", 2)
+			m, err := strconv.Atoi(parts[1])
+			if err != nil || m <= 24 || m > 32 {
+				return nil, fmt.Errorf("%s has invalid classless label %s", zone, label)
+			}
+			mask = m
+			label = parts[0]
+		}
+		n, err := strconv.Atoi(label)
+		if err != nil || n < 0 || n > 255 || strconv.Itoa(n) != label {
+			return nil, fmt.Errorf("%s has invalid octet %s", zone, label)
+		}
+		ip[pos] = byte(n)
+	}
+
+	c := NewIP(ip).CIDR(mask)
+	if !c.FirstIP().Equal(c.IP) {
+		return nil, fmt.Errorf("%s is not aligned to /%d", zone, mask)
+	}
+	return c, nil
+}
+
+func parseIPv6ReverseZone(zone, prefix string) (*CIDR, error) {
+	labels := splitReverseLabels(prefix)
+	if len(labels) > 32 {
+		return nil, fmt.Errorf("%s has too many labels", zone)
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	for i, label := range labels {
+		pos := len(labels) - 1 - i
+		if len(label) != 1 || strings.IndexByte(hexDigits, label[0]) == -1 {
+			return nil, fmt.Errorf("%s has invalid nibble %s", zone, label)
+		}
+		n := byte(strings.IndexByte(hexDigits, label[0]))
+		if pos%2 == 0 {
+			n <<= 4
+		}
+		ip[pos/2] |= n
+	}
+	return (&IP{IP: ip, Ver: IPV6}).CIDR(len(labels) * 4), nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIP_ReverseName(t *testing.T) {
+	assert.Equal(t, "4.3.2.1.in-addr.arpa", ParseIP("1.2.3.4").ReverseName())
+	assert.Equal(t, "b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.4.ip6.arpa",
+		ParseIP("4321:0:1:2:3:4:567:89ab").ReverseName())
+
+	ip, err := ParseReverseName("4.3.2.1.IN-ADDR.ARPA.")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip.String())
+	ip, err = ParseReverseName("b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.4.ip6.arpa")
+	assert.NoError(t, err)
+	assert.Equal(t, "4321:0:1:2:3:4:567:89ab", ip.String())
+
+	_, err = ParseReverseName("2.1.in-addr.arpa")
+	assert.Error(t, err)
+	_, err = ParseReverseName("256.3.2.1.in-addr.arpa")
+	assert.Error(t, err)
+	_, err = ParseReverseName("example.com")
+	assert.Error(t, err)
+}
+
+func TestCIDR_ReverseZones(t *testing.T) {
+	assert.Equal(t, []string{"10.in-addr.arpa"}, ParseCIDR("10.0.0.0/8").ReverseZones())
+	assert.Equal(t, []string{"2.0.192.in-addr.arpa"}, ParseCIDR("192.0.2.0/24").ReverseZones())
+	assert.Equal(t, []string{"168.192.in-addr.arpa", "169.192.in-addr.arpa"}, ParseCIDR("192.168.0.0/15").ReverseZones())
+	assert.Len(t, ParseCIDR("10.0.0.0/17").ReverseZones(), 128)
+	assert.Equal(t, []string{"64/26.2.0.192.in-addr.arpa"}, ParseCIDR("192.0.2.100/26").ReverseZones())
+	assert.Equal(t, []string{"5.2.0.192.in-addr.arpa"}, ParseCIDR("192.0.2.5/32").ReverseZones())
+	assert.Equal(t, []string{"in-addr.arpa"}, ParseCIDR("0.0.0.0/0").ReverseZones())
+
+	assert.Equal(t, []string{"8.b.d.0.1.0.0.2.ip6.arpa"}, ParseCIDR("2001:db8::/32").ReverseZones())
+	assert.Equal(t, []string{"0.8.b.d.0.1.0.0.2.ip6.arpa", "1.8.b.d.0.1.0.0.2.ip6.arpa"}, ParseCIDR("2001:db8::/35").ReverseZones())
+
+	for _, s := range []string{"10.0.0.0/8", "192.0.2.0/24", "192.0.2.64/26", "2001:db8::/32", "2001:db8:1::/48", "0.0.0.0/0"} {
+		zones := ParseCIDR(s).ReverseZones()
+		c, err := ParseReverseZone(zones[0])
+		assert.NoError(t, err)
+		assert.Equal(t, s, c.String())
+	}
+
+	_, err := ParseReverseZone("65/26.2.0.192.in-addr.arpa")
+	assert.Error(t, err)
+	_, err = ParseReverseZone("x.8.b.d.0.1.0.0.2.ip6.arpa")
+	assert.Error(t, err)
+}
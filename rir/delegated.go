@@ -0,0 +1,252 @@
+package rir
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/chainreactors/utils"
+	"github.com/chainreactors/utils/iputils"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// registries of delegated statistics
+const (
+	AFRINIC = "afrinic"
+	APNIC   = "apnic"
+	ARIN    = "arin"
+	LACNIC  = "lacnic"
+	RIPENCC = "ripencc"
+)
+
+// types of resource
+const (
+	TypeIPv4 = "ipv4"
+	TypeIPv6 = "ipv6"
+	TypeASN  = "asn"
+)
+
+// status of resource
+const (
+	StatusAllocated = "allocated"
+	StatusAssigned  = "assigned"
+	StatusAvailable = "available"
+	StatusReserved  = "reserved"
+)
+
+// Record one row of RIR delegated statistics, e.g.
+// apnic|CN|ipv4|1.0.1.0|256|20110414|allocated|A92E1062
+type Record struct {
+	Registry string
+	CC       string
+	Type     string
+	Start    string
+	// Value count of addresses for ipv4, prefix length for ipv6, count of as numbers for asn
+	Value  uint64
+	Date   string
+	Status string
+	// OpaqueID identify the holder of resource, only in extended format
+	OpaqueID   string
+	Extensions []string
+}
+
+func (r *Record) String() string {
+	fields := []string{r.Registry, r.CC, r.Type, r.Start, strconv.FormatUint(r.Value, 10), r.Date, r.Status}
+	if r.OpaqueID != "" || len(r.Extensions) > 0 {
+		fields = append(fields, r.OpaqueID)
+		fields = append(fields, r.Extensions...)
+	}
+	return strings.Join(fields, "|")
+}
+
+// CIDRs convert start and value of ip record to cidrs, return nil for asn record
+func (r *Record) CIDRs() utils.CIDRs {
+	start := net.ParseIP(r.Start)
+	if start == nil || r.Value == 0 {
+		return nil
+	}
+	switch r.Type {
+	case TypeIPv4:
+		if start.To4() == nil {
+			return nil
+		}
+		end := new(big.Int).SetBytes(start.To4())
+		end.Add(end, new(big.Int).SetUint64(r.Value-1))
+		if end.BitLen() > 32 {
+			return nil
+		}
+		nets, err := iputils.GetCIDRFromIPRange(start.To16(), iputils.IntegerToIP(end, 32).To16())
+		if err != nil {
+			return nil
+		}
+		cs := make(utils.CIDRs, 0, len(nets))
+		for _, n := range nets {
+			cs = append(cs, utils.NewCIDRFromNet(n))
+		}
+		return cs
+	case TypeIPv6:
+		if r.Value > 128 {
+			return nil
+		}
+		return utils.CIDRs{utils.NewIP(start).CIDR(int(r.Value)).FirstIP().CIDR(int(r.Value))}
+	}
+	return nil
+}
+
+func parseRecord(line string) (*Record, error) {
+	fields := strings.Split(line, "|")
+	if len(fields) < 7 {
+		return nil, fmt.Errorf("expected at least 7 fields, got %d", len(fields))
+	}
+	value, err := strconv.ParseUint(fields[4], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s", fields[4])
+	}
+	r := &Record{
+		Registry: strings.ToLower(fields[0]),
+		CC:       strings.ToUpper(fields[1]),
+		Type:     strings.ToLower(fields[2]),
+		Start:    fields[3],
+		Value:    value,
+		Date:     fields[5],
+		Status:   strings.ToLower(fields[6]),
+	}
+	if len(fields) > 7 {
+		r.OpaqueID = fields[7]
+		r.Extensions = fields[8:]
+	}
+	switch r.Type {
+	case TypeIPv4, TypeIPv6:
+		if net.ParseIP(r.Start) == nil {
+			return nil, fmt.Errorf("invalid start address %s", r.Start)
+		}
+	}
+	return r, nil
+}
+
+// Records records of one or more delegated statistics files
+type Records []*Record
+
+// Parse parse delegated-*-latest or delegated-*-extended-latest format, header, summary and comment lines are skipped
+func Parse(reader io.Reader) (Records, error) {
+	var records Records
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if isHeader(fields) || len(fields) >= 6 && fields[5] == "summary" {
+			continue
+		}
+		r, err := parseRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineno, err.Error())
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// isHeader version line, e.g. 2|apnic|20240417|71252|19830613|20240416|+1000
+func isHeader(fields []string) bool {
+	_, err := strconv.ParseFloat(fields[0], 64)
+	return err == nil
+}
+
+// ParseFile parse delegated statistics files, records of all files are merged
+func ParseFile(filenames ...string) (Records, error) {
+	var records Records
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err.Error())
+		}
+		records = append(records, rs...)
+	}
+	return records, nil
+}
+
+// Filter return records matched fn
+func (rs Records) Filter(fn func(r *Record) bool) Records {
+	var filtered Records
+	for _, r := range rs {
+		if fn(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func (rs Records) filterField(field func(r *Record) string, values []string) Records {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = true
+	}
+	return rs.Filter(func(r *Record) bool {
+		return set[strings.ToLower(field(r))]
+	})
+}
+
+// ByCountry filter by ISO 3166 country codes, case insensitive
+func (rs Records) ByCountry(cc ...string) Records {
+	return rs.filterField(func(r *Record) string { return r.CC }, cc)
+}
+
+func (rs Records) ByRegistry(registry ...string) Records {
+	return rs.filterField(func(r *Record) string { return r.Registry }, registry)
+}
+
+func (rs Records) ByStatus(status ...string) Records {
+	return rs.filterField(func(r *Record) string { return r.Status }, status)
+}
+
+func (rs Records) ByOpaqueID(id ...string) Records {
+	return rs.filterField(func(r *Record) string { return r.OpaqueID }, id)
+}
+
+func (rs Records) ByType(typ ...string) Records {
+	return rs.filterField(func(r *Record) string { return r.Type }, typ)
+}
+
+// IPv4 return ipv4 records
+func (rs Records) IPv4() Records {
+	return rs.ByType(TypeIPv4)
+}
+
+// IPv6 return ipv6 records
+func (rs Records) IPv6() Records {
+	return rs.ByType(TypeIPv6)
+}
+
+// Delegated return allocated and assigned records, skip available and reserved space
+func (rs Records) Delegated() Records {
+	return rs.ByStatus(StatusAllocated, StatusAssigned)
+}
+
+// CIDRs return the minimal and sorted cidrs of all ip records, ipv4 first.
+// result can be used by Range, Iterator and Permutation of utils.CIDRs directly
+func (rs Records) CIDRs() utils.CIDRs {
+	set := utils.NewIPSet(nil)
+	for _, r := range rs {
+		for _, c := range r.CIDRs() {
+			set.AddCIDR(c)
+		}
+	}
+	return set.CIDRs()
+}
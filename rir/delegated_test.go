@@ -0,0 +1,55 @@
+package rir
+
+import (
+	"github.com/chainreactors/utils"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const delegated = `# comment
+2|apnic|20240417|7|19830613|20240416|+1000
+apnic|*|asn|*|1|summary
+apnic|*|ipv4|*|5|summary
+apnic|*|ipv6|*|1|summary
+apnic|JP|asn|173|1|20020801|allocated|A91A7381
+apnic|CN|ipv4|1.0.1.0|256|20110414|allocated|A92E1062
+apnic|CN|ipv4|1.0.2.0|512|20110414|allocated|A92E1062
+apnic|CN|ipv4|1.0.8.0|768|20110412|allocated|A92319D5
+apnic|AU|ipv4|1.1.1.0|256|20110811|assigned|A91872ED
+apnic||ipv4|1.0.64.0|100|00000000|available
+apnic|CN|ipv6|2001:250::|35|20000426|allocated|A92E1062
+`
+
+func TestParse(t *testing.T) {
+	rs, err := Parse(strings.NewReader(delegated))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, rs, 7)
+	assert.Equal(t, "apnic|CN|ipv4|1.0.1.0|256|20110414|allocated|A92E1062", rs[1].String())
+
+	cn := rs.ByCountry("cn")
+	assert.Len(t, cn, 4)
+	assert.Equal(t, []string{"1.0.1.0/24", "1.0.2.0/23", "1.0.8.0/23", "1.0.10.0/24", "2001:250::/35"}, cn.CIDRs().Strings())
+	assert.Equal(t, []string{"1.0.1.0/24", "1.0.2.0/23"}, rs.ByOpaqueID("A92E1062").IPv4().CIDRs().Strings())
+	assert.Equal(t, []string{"1.1.1.0/24"}, rs.ByStatus(StatusAssigned).CIDRs().Strings())
+	assert.Len(t, rs.ByRegistry(APNIC).Delegated(), 6)
+	assert.Len(t, rs.ByRegistry(ARIN), 0)
+	assert.Nil(t, rs[0].CIDRs())
+
+	// count of available block is not power of 2
+	assert.Equal(t, []string{"1.0.64.0/26", "1.0.64.64/27", "1.0.64.96/30"}, rs.ByStatus(StatusAvailable).CIDRs().Strings())
+
+	// plug into generators
+	assert.Equal(t, int64(256+512+768), rs.ByCountry("CN").IPv4().CIDRs().Count().Int64())
+	assert.True(t, cn.CIDRs().IPSet().ContainsIP(utils.ParseIP("1.0.9.9")))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("apnic|CN|ipv4|1.0.1.0|abc|20110414|allocated"))
+	assert.EqualError(t, err, "line 1: invalid value abc")
+
+	_, err = Parse(strings.NewReader("apnic|CN|ipv4|1.0.1.0"))
+	assert.Error(t, err)
+}
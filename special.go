@@ -0,0 +1,182 @@
+package utils
+
+// kinds of special-purpose address
+const (
+	KindThisNetwork   = "this-network"
+	KindPrivate       = "private"
+	KindCGNAT         = "cgnat"
+	KindLoopback      = "loopback"
+	KindLinkLocal     = "link-local"
+	KindProtocol      = "protocol-assignments"
+	KindDocumentation = "documentation"
+	KindBenchmarking  = "benchmarking"
+	KindMulticast     = "multicast"
+	KindReserved      = "reserved"
+	KindBroadcast     = "broadcast"
+	KindUnspecified   = "unspecified"
+	KindIPv4Mapped    = "ipv4-mapped"
+	KindTranslation   = "translation"
+	KindDiscard       = "discard"
+	Kind6to4          = "6to4"
+	KindTeredo        = "teredo"
+	KindUniqueLocal   = "unique-local"
+	KindSiteLocal     = "site-local"
+	KindAnycast       = "anycast"
+	KindORCHID        = "orchid"
+	KindSRv6          = "srv6"
+)
+
+// IPClass special-purpose address block, from IANA IPv4/IPv6 special-purpose address registry
+type IPClass struct {
+	CIDR *CIDR
+	Name string
+	Kind string
+	RFC  string
+	// Global globally reachable, addresses in block with Global false are bogon on the internet
+	Global bool
+}
+
+func (c *IPClass) String() string {
+	return c.CIDR.String() + " " + c.Name + " (" + c.RFC + ")"
+}
+
+func newIPClass(cidr, name, kind, rfc string, global bool) *IPClass {
+	return &IPClass{CIDR: ParseCIDR(cidr), Name: name, Kind: kind, RFC: rfc, Global: global}
+}
+
+var (
+	SpecialIPClasses = []*IPClass{
+		newIPClass("0.0.0.0/8", "This network", KindThisNetwork, "RFC 791", false),
+		newIPClass("10.0.0.0/8", "Private-Use", KindPrivate, "RFC 1918", false),
+		newIPClass("100.64.0.0/10", "Shared Address Space", KindCGNAT, "RFC 6598", false),
+		newIPClass("127.0.0.0/8", "Loopback", KindLoopback, "RFC 1122", false),
+		newIPClass("169.254.0.0/16", "Link Local", KindLinkLocal, "RFC 3927", false),
+		newIPClass("172.16.0.0/12", "Private-Use", KindPrivate, "RFC 1918", false),
+		newIPClass("192.0.0.0/24", "IETF Protocol Assignments", KindProtocol, "RFC 6890", false),
+		newIPClass("192.0.0.9/32", "Port Control Protocol Anycast", KindAnycast, "RFC 7723", true),
+		newIPClass("192.0.0.10/32", "Traversal Using Relays around NAT Anycast", KindAnycast, "RFC 8155", true),
+		newIPClass("192.0.2.0/24", "Documentation (TEST-NET-1)", KindDocumentation, "RFC 5737", false),
+		newIPClass("192.31.196.0/24", "AS112-v4", KindAnycast, "RFC 7535", true),
+		newIPClass("192.52.193.0/24", "AMT", KindAnycast, "RFC 7450", true),
+		newIPClass("192.88.99.0/24", "Deprecated (6to4 Relay Anycast)", Kind6to4, "RFC 7526", false),
+		newIPClass("192.168.0.0/16", "Private-Use", KindPrivate, "RFC 1918", false),
+		newIPClass("192.175.48.0/24", "Direct Delegation AS112 Service", KindAnycast, "RFC 7534", true),
+		newIPClass("198.18.0.0/15", "Benchmarking", KindBenchmarking, "RFC 2544", false),
+		newIPClass("198.51.100.0/24", "Documentation (TEST-NET-2)", KindDocumentation, "RFC 5737", false),
+		newIPClass("203.0.113.0/24", "Documentation (TEST-NET-3)", KindDocumentation, "RFC 5737", false),
+		newIPClass("224.0.0.0/4", "Multicast", KindMulticast, "RFC 5771", false),
+		newIPClass("240.0.0.0/4", "Reserved", KindReserved, "RFC 1112", false),
+		newIPClass("255.255.255.255/32", "Limited Broadcast", KindBroadcast, "RFC 919", false),
+
+		newIPClass("::/128", "Unspecified Address", KindUnspecified, "RFC 4291", false),
+		newIPClass("::1/128", "Loopback Address", KindLoopback, "RFC 4291", false),
+		newIPClass("::ffff:0:0/96", "IPv4-mapped Address", KindIPv4Mapped, "RFC 4291", false),
+		newIPClass("64:ff9b::/96", "IPv4-IPv6 Translation", KindTranslation, "RFC 6052", true),
+		newIPClass("64:ff9b:1::/48", "IPv4-IPv6 Translation", KindTranslation, "RFC 8215", false),
+		newIPClass("100::/64", "Discard-Only Address Block", KindDiscard, "RFC 6666", false),
+		newIPClass("2001::/23", "IETF Protocol Assignments", KindProtocol, "RFC 2928", false),
+		newIPClass("2001::/32", "TEREDO", KindTeredo, "RFC 4380", false),
+		newIPClass("2001:1::1/128", "Port Control Protocol Anycast", KindAnycast, "RFC 7723", true),
+		newIPClass("2001:1::2/128", "Traversal Using Relays around NAT Anycast", KindAnycast, "RFC 8155", true),
+		newIPClass("2001:2::/48", "Benchmarking", KindBenchmarking, "RFC 5180", false),
+		newIPClass("2001:3::/32", "AMT", KindAnycast, "RFC 7450", true),
+		newIPClass("2001:4:112::/48", "AS112-v6", KindAnycast, "RFC 7535", true),
+		newIPClass("2001:10::/28", "Deprecated (previously ORCHID)", KindORCHID, "RFC 4843", false),
+		newIPClass("2001:20::/28", "ORCHIDv2", KindORCHID, "RFC 7343", true),
+		newIPClass("2001:db8::/32", "Documentation", KindDocumentation, "RFC 3849", false),
+		newIPClass("2002::/16", "6to4", Kind6to4, "RFC 3056", false),
+		newIPClass("2620:4f:8000::/48", "Direct Delegation AS112 Service", KindAnycast, "RFC 7534", true),
+		newIPClass("3fff::/20", "Documentation", KindDocumentation, "RFC 9637", false),
+		newIPClass("5f00::/16", "Segment Routing (SRv6) SIDs", KindSRv6, "RFC 9602", false),
+		newIPClass("fc00::/7", "Unique-Local", KindUniqueLocal, "RFC 4193", false),
+		newIPClass("fe80::/10", "Link-Local Unicast", KindLinkLocal, "RFC 4291", false),
+		newIPClass("fec0::/10", "Deprecated (Site-Local)", KindSiteLocal, "RFC 3879", false),
+		newIPClass("ff00::/8", "Multicast", KindMulticast, "RFC 4291", false),
+	}
+
+	// IPv6 outside global unicast 2000::/3 is reserved by IETF
+	ipv4All           = ParseCIDR("0.0.0.0/0")
+	ipv6GlobalUnicast = ParseCIDR("2000::/3")
+	ipv6Reserved      = newIPClass("::/0", "Reserved by IETF", KindReserved, "RFC 4291", false)
+
+	specialIPClassMap = newSpecialIPClassMap()
+)
+
+func newSpecialIPClassMap() *CIDRMap {
+	m := NewCIDRMap()
+	for _, class := range SpecialIPClasses {
+		m.Insert(class.CIDR, class)
+	}
+	return m
+}
+
+// Class return the most specific special-purpose block containing ip, nil for ordinary public address
+func (ip *IP) Class() *IPClass {
+	if e, ok := specialIPClassMap.Lookup(ip); ok {
+		return e.Value.(*IPClass)
+	}
+	if ip.Ver == IPV6 && !ipv6GlobalUnicast.ContainsIP(ip) {
+		return ipv6Reserved
+	}
+	return nil
+}
+
+// IsPublic return true if ip is globally reachable
+func (ip *IP) IsPublic() bool {
+	class := ip.Class()
+	return class == nil || class.Global
+}
+
+func (ip *IP) IsPrivate() bool {
+	class := ip.Class()
+	return class != nil && (class.Kind == KindPrivate || class.Kind == KindUniqueLocal)
+}
+
+// IsBogon return true if ip should never appear on the public internet
+func (ip *IP) IsBogon() bool {
+	return !ip.IsPublic()
+}
+
+// Classes return all special-purpose blocks overlapping cidr
+func (c *CIDR) Classes() []*IPClass {
+	var classes []*IPClass
+	for _, class := range SpecialIPClasses {
+		if class.CIDR.Ver == c.Ver && (c.ContainsIP(class.CIDR.IP) || class.CIDR.ContainsIP(c.FirstIP())) {
+			classes = append(classes, class)
+		}
+	}
+	if c.Ver == IPV6 && (c.Mask < ipv6GlobalUnicast.Mask || !ipv6GlobalUnicast.ContainsIP(c.FirstIP())) {
+		classes = append(classes, ipv6Reserved)
+	}
+	return classes
+}
+
+// IsPublic return true if every ip of cidr is globally reachable
+func (c *CIDR) IsPublic() bool {
+	for _, class := range c.Classes() {
+		if !class.Global {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterPublic return the minimal cidrs of globally reachable addresses in cs
+func (cs CIDRs) FilterPublic() CIDRs {
+	var bogons CIDRs
+	for _, class := range SpecialIPClasses {
+		if !class.Global {
+			bogons = append(bogons, class.CIDR)
+		}
+	}
+	public := cs.Subtract(bogons)
+
+	// keep ipv6 only in global unicast, global blocks inside bogons have been removed above, add them back
+	var global CIDRs
+	for _, class := range SpecialIPClasses {
+		if class.Global {
+			global = append(global, class.CIDR)
+		}
+	}
+	return public.Intersect(CIDRs{ipv4All, ipv6GlobalUnicast}).Union(cs.Intersect(global))
+}
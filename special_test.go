@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIP_Class(t *testing.T) {
+	testCases := []struct {
+		ip   string
+		kind string
+		rfc  string
+	}{
+		{"10.1.2.3", KindPrivate, "RFC 1918"},
+		{"100.64.1.1", KindCGNAT, "RFC 6598"},
+		{"127.0.0.1", KindLoopback, "RFC 1122"},
+		{"169.254.169.254", KindLinkLocal, "RFC 3927"},
+		{"224.0.0.251", KindMulticast, "RFC 5771"},
+		{"198.51.100.7", KindDocumentation, "RFC 5737"},
+		{"198.19.0.1", KindBenchmarking, "RFC 2544"},
+		{"192.0.0.9", KindAnycast, "RFC 7723"},
+		{"::1", KindLoopback, "RFC 4291"},
+		{"fe80::1", KindLinkLocal, "RFC 4291"},
+		{"2001:db8::1", KindDocumentation, "RFC 3849"},
+		{"2001:0:53ab::1", KindTeredo, "RFC 4380"},
+		{"2002:c000:204::1", Kind6to4, "RFC 3056"},
+		{"fd00::1", KindUniqueLocal, "RFC 4193"},
+		{"4000::1", KindReserved, "RFC 4291"},
+	}
+	for _, tc := range testCases {
+		class := ParseIP(tc.ip).Class()
+		if assert.NotNil(t, class, tc.ip) {
+			assert.Equal(t, tc.kind, class.Kind, tc.ip)
+			assert.Equal(t, tc.rfc, class.RFC, tc.ip)
+		}
+	}
+
+	assert.Nil(t, ParseIP("8.8.8.8").Class())
+	assert.Nil(t, ParseIP("2606:4700::1111").Class())
+	assert.True(t, ParseIP("8.8.8.8").IsPublic())
+	assert.True(t, ParseIP("192.0.0.9").IsPublic())
+	assert.False(t, ParseIP("100.100.1.1").IsPublic())
+	assert.True(t, ParseIP("172.20.1.1").IsPrivate())
+	assert.True(t, ParseIP("240.0.0.1").IsBogon())
+}
+
+func TestCIDRs_FilterPublic(t *testing.T) {
+	assert.False(t, ParseCIDR("192.168.0.0/15").IsPublic())
+	assert.True(t, ParseCIDR("8.8.8.0/24").IsPublic())
+	assert.False(t, ParseCIDR("::/0").IsPublic())
+
+	cs := ParseCIDRs([]string{"172.0.0.0/8", "192.0.0.0/24", "2001:db8::/31"})
+	assert.Equal(t, []string{
+		"172.0.0.0/12", "172.32.0.0/11", "172.64.0.0/10", "172.128.0.0/9",
+		"192.0.0.9/32", "192.0.0.10/32",
+		"2001:db9::/32",
+	}, cs.FilterPublic().Strings())
+}
@@ -0,0 +1,92 @@
+package sysnet
+
+import (
+	"fmt"
+	"github.com/chainreactors/utils"
+	"io"
+	"net"
+	"strconv"
+)
+
+// flags of arp entry, see linux/if_arp.h
+const (
+	ATFComplete  = 0x02
+	ATFPermanent = 0x04
+)
+
+// Neighbor arp entry
+type Neighbor struct {
+	IP           *utils.IP
+	HWType       uint32
+	Flags        uint32
+	HardwareAddr net.HardwareAddr
+	Iface        string
+}
+
+// IsComplete hardware address is resolved
+func (n *Neighbor) IsComplete() bool {
+	return n.Flags&ATFComplete != 0
+}
+
+func (n *Neighbor) IsPermanent() bool {
+	return n.Flags&ATFPermanent != 0
+}
+
+type Neighbors []*Neighbor
+
+// IPs return ips of complete entries
+func (ns Neighbors) IPs() utils.IPs {
+	var ips utils.IPs
+	for _, n := range ns {
+		if n.IsComplete() {
+			ips = append(ips, n.IP)
+		}
+	}
+	return ips
+}
+
+// ParseARP parse arp table of /proc/net/arp, e.g.
+// 192.0.2.1        0x1         0x2         02:fc:00:00:00:05     *        eth0
+func ParseARP(reader io.Reader) (Neighbors, error) {
+	var ns Neighbors
+	err := forEachFields(reader, true, func(fields []string) error {
+		if len(fields) < 6 {
+			return fmt.Errorf("expected 6 fields, got %d", len(fields))
+		}
+		ip := net.ParseIP(fields[0])
+		if ip == nil {
+			return fmt.Errorf("invalid address %s", fields[0])
+		}
+		n := &Neighbor{IP: utils.NewIP(ip), Iface: fields[5]}
+		hwType, err := strconv.ParseUint(fields[1], 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid hw type %s", fields[1])
+		}
+		flags, err := strconv.ParseUint(fields[2], 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid flags %s", fields[2])
+		}
+		n.HWType, n.Flags = uint32(hwType), uint32(flags)
+		// incomplete entry has hardware address of zero
+		if n.HardwareAddr, err = net.ParseMAC(fields[3]); err != nil {
+			return fmt.Errorf("invalid hw address %s", fields[3])
+		}
+		ns = append(ns, n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// ReadNeighbors read arp table from ProcARP, ipv6 neighbors are not in procfs
+func ReadNeighbors() (Neighbors, error) {
+	var ns Neighbors
+	err := parseFile(ProcARP, func(reader io.Reader) error {
+		var err error
+		ns, err = ParseARP(reader)
+		return err
+	})
+	return ns, err
+}
@@ -0,0 +1,26 @@
+package sysnet
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseARP(t *testing.T) {
+	defer func(arp string) { ProcARP = arp }(ProcARP)
+	ProcARP = "testdata/arp"
+	ns, err := ReadNeighbors()
+	if !assert.NoError(t, err) || !assert.Len(t, ns, 3) {
+		return
+	}
+	assert.Equal(t, "192.0.2.1", ns[0].IP.String())
+	assert.Equal(t, "02:fc:00:00:00:05", ns[0].HardwareAddr.String())
+	assert.Equal(t, "eth0", ns[0].Iface)
+	assert.True(t, ns[0].IsComplete())
+	assert.False(t, ns[1].IsComplete())
+	assert.True(t, ns[2].IsPermanent())
+	assert.Equal(t, []string{"192.0.2.1", "192.168.1.1"}, ns.IPs().Strings())
+
+	_, err = ParseARP(strings.NewReader("IP address\n10.0.0.1 0x1 0x2 zz:00 * eth0\n"))
+	assert.EqualError(t, err, "line 2: invalid hw address zz:00")
+}
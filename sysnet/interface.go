@@ -0,0 +1,76 @@
+package sysnet
+
+import (
+	"github.com/chainreactors/utils"
+	"net"
+)
+
+// Interface network interface with its addresses, IP of every CIDR is the interface address, e.g. 192.168.1.10/24
+type Interface struct {
+	Name         string
+	Index        int
+	MTU          int
+	HardwareAddr net.HardwareAddr
+	Flags        net.Flags
+	CIDRs        utils.CIDRs
+}
+
+func (i *Interface) IsUp() bool {
+	return i.Flags&net.FlagUp != 0
+}
+
+func (i *Interface) IsLoopback() bool {
+	return i.Flags&net.FlagLoopback != 0
+}
+
+// Interfaces list interfaces of local host by net.Interfaces, work on all platforms
+func Interfaces() ([]*Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	is := make([]*Interface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		i := &Interface{
+			Name:         iface.Name,
+			Index:        iface.Index,
+			MTU:          iface.MTU,
+			HardwareAddr: iface.HardwareAddr,
+			Flags:        iface.Flags,
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok {
+				if c := utils.NewCIDRFromNet(ipnet); c != nil {
+					i.CIDRs = append(i.CIDRs, c)
+				}
+			}
+		}
+		is = append(is, i)
+	}
+	return is, nil
+}
+
+// LocalNetworks return networks of up and non-loopback interfaces for scan scope, ipv6 link-local networks are skipped
+func LocalNetworks() (utils.CIDRs, error) {
+	is, err := Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var cs utils.CIDRs
+	for _, i := range is {
+		if !i.IsUp() || i.IsLoopback() {
+			continue
+		}
+		for _, c := range i.CIDRs {
+			if c.IP.IP.IsLinkLocalUnicast() && c.Ver == utils.IPV6 {
+				continue
+			}
+			cs = append(cs, c.FirstIP().CIDR(c.Mask))
+		}
+	}
+	return cs, nil
+}
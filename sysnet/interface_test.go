@@ -0,0 +1,23 @@
+package sysnet
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInterfaces(t *testing.T) {
+	is, err := Interfaces()
+	assert.NoError(t, err)
+	for _, i := range is {
+		for _, c := range i.CIDRs {
+			assert.NotNil(t, c.IP)
+		}
+	}
+
+	cs, err := LocalNetworks()
+	assert.NoError(t, err)
+	for _, c := range cs {
+		assert.False(t, c.IP.IP.IsLoopback(), c.String())
+		assert.Equal(t, c.FirstIP().String(), c.IP.String())
+	}
+}
@@ -0,0 +1,232 @@
+package sysnet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/chainreactors/utils"
+	"io"
+	"net"
+	"os"
+	"strconv"
+)
+
+// flags of route, see linux/route.h
+const (
+	RTFUp      = 0x0001
+	RTFGateway = 0x0002
+	RTFHost    = 0x0004
+	RTFReject  = 0x0200
+)
+
+type Route struct {
+	Iface       string
+	Destination *utils.CIDR
+	// Gateway next hop, nil if destination is on-link
+	Gateway *utils.IP
+	Flags   uint32
+	Metric  uint32
+	// MTU only in ipv4 routing table, 0 means default of interface
+	MTU uint32
+}
+
+func (r *Route) IsUp() bool {
+	return r.Flags&RTFUp != 0
+}
+
+func (r *Route) IsReject() bool {
+	return r.Flags&RTFReject != 0
+}
+
+func (r *Route) IsDefault() bool {
+	return r.Destination.Mask == 0
+}
+
+func (r *Route) String() string {
+	s := r.Destination.String()
+	if r.Gateway != nil {
+		s += " via " + r.Gateway.String()
+	}
+	return s + " dev " + r.Iface
+}
+
+type Routes []*Route
+
+// Default return the default route of ip version with the lowest metric, nil if not found
+func (rs Routes) Default(ver int) *Route {
+	var def *Route
+	for _, r := range rs {
+		if r.Destination.Ver != ver || !r.IsDefault() || !r.IsUp() || r.IsReject() || r.Gateway == nil {
+			continue
+		}
+		if def == nil || r.Metric < def.Metric {
+			def = r
+		}
+	}
+	return def
+}
+
+// Lookup return the route of ip by longest prefix match and then the lowest metric, nil if not found
+func (rs Routes) Lookup(ip *utils.IP) *Route {
+	var match *Route
+	for _, r := range rs {
+		if r.Destination.Ver != ip.Ver || !r.IsUp() || r.IsReject() || !r.Destination.ContainsIP(ip) {
+			continue
+		}
+		if match == nil || r.Destination.Mask > match.Destination.Mask ||
+			r.Destination.Mask == match.Destination.Mask && r.Metric < match.Metric {
+			match = r
+		}
+	}
+	return match
+}
+
+// ParseRoute parse ipv4 routing table of /proc/net/route, e.g.
+// eth0	000200C0	00000000	0001	0	0	0	00FFFFFF	0	0	0
+// addresses are hex of little endian host byte order
+func ParseRoute(reader io.Reader) (Routes, error) {
+	var rs Routes
+	err := forEachFields(reader, true, func(fields []string) error {
+		if len(fields) < 11 {
+			return fmt.Errorf("expected 11 fields, got %d", len(fields))
+		}
+		dest, err := parseHexIPv4(fields[1])
+		if err != nil {
+			return err
+		}
+		gateway, err := parseHexIPv4(fields[2])
+		if err != nil {
+			return err
+		}
+		mask, err := parseHexIPv4(fields[7])
+		if err != nil {
+			return err
+		}
+		prefix, err := utils.IPMaskToPrefixLength(net.IPMask(mask))
+		if err != nil {
+			return err
+		}
+		r := &Route{Iface: fields[0], Destination: utils.NewIP(dest).CIDR(prefix)}
+		if !gateway.Equal(net.IPv4zero) {
+			r.Gateway = utils.NewIP(gateway)
+		}
+		if r.Flags, err = parseUint32(fields[3], 16); err != nil {
+			return err
+		}
+		if r.Metric, err = parseUint32(fields[6], 10); err != nil {
+			return err
+		}
+		if r.MTU, err = parseUint32(fields[8], 10); err != nil {
+			return err
+		}
+		rs = append(rs, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// ParseIPv6Route parse ipv6 routing table of /proc/net/ipv6_route, e.g.
+// fd000000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     eth0
+// fields are destination, prefix, source, source prefix, next hop, metric, refcnt, use, flags and interface, all in hex
+func ParseIPv6Route(reader io.Reader) (Routes, error) {
+	var rs Routes
+	err := forEachFields(reader, false, func(fields []string) error {
+		if len(fields) < 10 {
+			return fmt.Errorf("expected 10 fields, got %d", len(fields))
+		}
+		dest, err := parseHexIPv6(fields[0])
+		if err != nil {
+			return err
+		}
+		prefix, err := parseUint32(fields[1], 16)
+		if err != nil || prefix > 128 {
+			return fmt.Errorf("invalid prefix %s", fields[1])
+		}
+		gateway, err := parseHexIPv6(fields[4])
+		if err != nil {
+			return err
+		}
+		r := &Route{Iface: fields[9], Destination: (&utils.IP{IP: dest, Ver: utils.IPV6}).CIDR(int(prefix))}
+		if !gateway.Equal(net.IPv6zero) {
+			r.Gateway = &utils.IP{IP: gateway, Ver: utils.IPV6}
+		}
+		if r.Metric, err = parseUint32(fields[5], 16); err != nil {
+			return err
+		}
+		if r.Flags, err = parseUint32(fields[8], 16); err != nil {
+			return err
+		}
+		rs = append(rs, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// ReadRoutes read ipv4 and ipv6 routing table from ProcRoute and ProcIPv6Route,
+// ipv6 routing table is skipped if ipv6 is disabled
+func ReadRoutes() (Routes, error) {
+	var rs Routes
+	err := parseFile(ProcRoute, func(reader io.Reader) error {
+		var err error
+		rs, err = ParseRoute(reader)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = parseFile(ProcIPv6Route, func(reader io.Reader) error {
+		rs6, err := ParseIPv6Route(reader)
+		rs = append(rs, rs6...)
+		return err
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// DefaultGateway return gateway of the default route, ipv4 is preferred
+func DefaultGateway() (*utils.IP, error) {
+	rs, err := ReadRoutes()
+	if err != nil {
+		return nil, err
+	}
+	for _, ver := range []int{utils.IPV4, utils.IPV6} {
+		if r := rs.Default(ver); r != nil {
+			return r.Gateway, nil
+		}
+	}
+	return nil, fmt.Errorf("default gateway not found")
+}
+
+func parseHexIPv4(s string) (net.IP, error) {
+	i, err := parseUint32(s, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s", s)
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.LittleEndian.PutUint32(ip, i)
+	return ip, nil
+}
+
+func parseHexIPv6(s string) (net.IP, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != net.IPv6len {
+		return nil, fmt.Errorf("invalid address %s", s)
+	}
+	return net.IP(b), nil
+}
+
+func parseUint32(s string, base int) (uint32, error) {
+	i, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %s", s)
+	}
+	return uint32(i), nil
+}
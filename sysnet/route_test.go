@@ -0,0 +1,83 @@
+package sysnet
+
+import (
+	"github.com/chainreactors/utils"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parseFixture(t *testing.T, filename string, parser func(f *os.File) error) {
+	f, err := os.Open(filename)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer f.Close()
+	assert.NoError(t, parser(f))
+}
+
+func TestParseRoute(t *testing.T) {
+	var rs Routes
+	parseFixture(t, "testdata/route", func(f *os.File) (err error) {
+		rs, err = ParseRoute(f)
+		return err
+	})
+	if !assert.Len(t, rs, 5) {
+		return
+	}
+	assert.Equal(t, "0.0.0.0/0 via 192.0.2.1 dev eth0", rs[0].String())
+	assert.Equal(t, uint32(100), rs[0].Metric)
+	assert.Equal(t, "192.0.2.0/24 dev eth0", rs[2].String())
+	assert.Nil(t, rs[2].Gateway)
+	assert.Equal(t, "10.0.0.0/16 via 10.8.0.1 dev tun0", rs[4].String())
+	assert.Equal(t, uint32(1400), rs[4].MTU)
+
+	assert.Equal(t, "192.0.2.1", rs.Default(utils.IPV4).Gateway.String())
+	assert.Nil(t, rs.Default(utils.IPV6))
+	assert.Equal(t, "tun0", rs.Lookup(utils.ParseIP("10.0.3.4")).Iface)
+	assert.Equal(t, "wlan0", rs.Lookup(utils.ParseIP("192.168.1.20")).Iface)
+	assert.Equal(t, "eth0", rs.Lookup(utils.ParseIP("8.8.8.8")).Iface)
+
+	_, err := ParseRoute(strings.NewReader("Iface\tDestination\neth0\tXYZ\t0\t0\t0\t0\t0\t0\t0\t0\t0\n"))
+	assert.EqualError(t, err, "line 2: invalid address XYZ")
+}
+
+func TestParseIPv6Route(t *testing.T) {
+	var rs Routes
+	parseFixture(t, "testdata/ipv6_route", func(f *os.File) (err error) {
+		rs, err = ParseIPv6Route(f)
+		return err
+	})
+	if !assert.Len(t, rs, 7) {
+		return
+	}
+	assert.Equal(t, "fd00::/64 dev eth0", rs[0].String())
+	assert.Equal(t, uint32(0x100), rs[0].Metric)
+	assert.Equal(t, "::/0 via fd00::1 dev eth0", rs[2].String())
+	assert.True(t, rs[6].IsReject())
+
+	def := rs.Default(utils.IPV6)
+	assert.Equal(t, "fd00::1", def.Gateway.String())
+	assert.Equal(t, "fd00::2/128", rs.Lookup(utils.ParseIP("fd00::2")).Destination.String())
+	assert.Equal(t, "ff00::/8", rs.Lookup(utils.ParseIP("ff02::1")).Destination.String())
+}
+
+func TestReadRoutes(t *testing.T) {
+	defer func(route, route6 string) {
+		ProcRoute, ProcIPv6Route = route, route6
+	}(ProcRoute, ProcIPv6Route)
+	ProcRoute, ProcIPv6Route = "testdata/route", "testdata/ipv6_route"
+	rs, err := ReadRoutes()
+	assert.NoError(t, err)
+	assert.Len(t, rs, 12)
+	gw, err := DefaultGateway()
+	assert.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", gw.String())
+
+	// ipv6 disabled
+	ProcIPv6Route = "testdata/not_exist"
+	rs, err = ReadRoutes()
+	assert.NoError(t, err)
+	assert.Len(t, rs, 5)
+}
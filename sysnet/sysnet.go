@@ -0,0 +1,51 @@
+// Package sysnet discover networking state of local host, interface addresses, routing table and arp table.
+// routing table and arp table are read from procfs, only available on linux
+package sysnet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// procfs files, can be changed to read from a chroot or a copy of another host
+var (
+	ProcRoute     = "/proc/net/route"
+	ProcIPv6Route = "/proc/net/ipv6_route"
+	ProcARP       = "/proc/net/arp"
+)
+
+// parseFile open filename and parse it by parser
+func parseFile(filename string, parser func(io.Reader) error) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := parser(f); err != nil {
+		return fmt.Errorf("%s: %s", filename, err.Error())
+	}
+	return nil
+}
+
+// forEachFields split every non-empty line to fields, header line is skipped if header is true
+func forEachFields(reader io.Reader, header bool, fn func(fields []string) error) error {
+	scanner := bufio.NewScanner(reader)
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		if header && lineno == 1 {
+			continue
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("line %d: %s", lineno, err.Error())
+		}
+	}
+	return scanner.Err()
+}
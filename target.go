@@ -0,0 +1,333 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// TargetParser parse target expressions, zero value is ready to use
+type TargetParser struct {
+	// Preset resolve port names and tags, PrePort is used if nil
+	Preset *PortPreset
+	// Resolver resolve hostnames, DefaultResolver is used if nil
+	Resolver Resolver
+	// DefaultPorts port spec of targets without ports, e.g. "80,443" or "top100".
+	// targets without ports yield no addr if DefaultPorts is empty, but are kept in Targets.CIDRs
+	DefaultPorts string
+}
+
+// ParseTargets parse target expressions with PrePort and DefaultResolver, see TargetParser.Parse
+func ParseTargets(targets []string, defaultPorts ...string) (*Targets, error) {
+	return (&TargetParser{DefaultPorts: strings.Join(defaultPorts, ",")}).Parse(targets)
+}
+
+func (p *TargetParser) preset() *PortPreset {
+	if p.Preset != nil {
+		return p.Preset
+	}
+	if PrePort != nil {
+		return PrePort
+	}
+	return NewPortPreset(nil)
+}
+
+// target one parsed expression
+type target struct {
+	cidrs   CIDRs
+	ports   []string
+	exclude bool
+}
+
+// Parse parse target expressions, support:
+//
+//	10.0.0.0/24:80,443,top100
+//	10.0.0.1-10.0.0.50:22
+//	example.com:8000-8100
+//	[2001:db8::1]:22
+//	2001:db8::/120
+//	!10.0.0.5, exclude ip of all ports
+//	!10.0.0.0/28:22, exclude only port 22
+//
+// blank and comment lines are skipped. wrong expressions are collected as ParseErrors,
+// the returned Targets always contains the valid ones
+func (p *TargetParser) Parse(exprs []string) (*Targets, error) {
+	var defaultPorts []string
+	var errs ParseErrors
+	if p.DefaultPorts != "" {
+		ports, err := p.preset().ParsePortStringE(p.DefaultPorts)
+		if err != nil {
+			return nil, err
+		}
+		defaultPorts = ports
+	}
+
+	var targets []*target
+	forEachLine(exprs, func(line string, lineno int) {
+		t, err := p.parseTarget(line)
+		if err != nil {
+			errs = append(errs, withLine(err, lineno))
+			return
+		}
+		if t.ports == nil && !t.exclude {
+			t.ports = defaultPorts
+		}
+		targets = append(targets, t)
+	})
+	return newTargets(targets), errs.err()
+}
+
+func (p *TargetParser) parseTarget(expr string) (*target, error) {
+	t := &target{}
+	s := expr
+	if strings.HasPrefix(s, "!") {
+		t.exclude = true
+		s = strings.TrimSpace(s[1:])
+	}
+	offset := len(expr) - len(s)
+
+	host, portSpec, portPos, err := splitTarget(s)
+	if err != nil {
+		return nil, newParseError(expr, offset, err)
+	}
+	if rs, err := ParseIPRange(host); err == nil {
+		t.cidrs = rs.CIDRs()
+	} else {
+		c, err := ParseCIDRE(host, p.Resolver)
+		if err != nil {
+			e := err.(*ParseError)
+			e.Input, e.Pos = expr, offset+strings.Index(s, host)+e.Pos
+			return nil, e
+		}
+		t.cidrs = CIDRs{c}
+	}
+
+	if portPos != -1 {
+		ports, err := p.preset().ParsePortStringE(portSpec)
+		if err != nil {
+			e := err.(*ParseError)
+			e.Input, e.Pos = expr, offset+portPos+e.Pos
+			return nil, e
+		}
+		t.ports = ports
+	}
+	return t, nil
+}
+
+// splitTarget split host and port spec, portPos is -1 if no port spec
+func splitTarget(s string) (host, ports string, portPos int, err error) {
+	if strings.HasPrefix(s, "[") {
+		i := strings.Index(s, "]")
+		if i == -1 {
+			return "", "", -1, ErrInvalidAddr
+		}
+		host, rest := s[1:i], s[i+1:]
+		if rest == "" {
+			return host, "", -1, nil
+		}
+		if rest[0] != ':' {
+			return "", "", -1, ErrInvalidAddr
+		}
+		return host, rest[1:], i + 2, nil
+	}
+	// ipv6 without brackets can not carry ports
+	if strings.Count(s, ":") > 1 {
+		return s, "", -1, nil
+	}
+	if i := strings.LastIndex(s, ":"); i != -1 {
+		return s[:i], s[i+1:], i + 1, nil
+	}
+	return s, "", -1, nil
+}
+
+// targetGroup ips and ports of one target, excluded ips of all ports are removed from set
+type targetGroup struct {
+	set   *IPSet
+	cidrs CIDRs
+	ports []string
+	index map[string]bool
+}
+
+func newTargetGroup(cidrs CIDRs, ports []string) *targetGroup {
+	g := &targetGroup{set: NewIPSet(cidrs), ports: ports, index: make(map[string]bool, len(ports))}
+	for _, port := range ports {
+		g.index[port] = true
+	}
+	return g
+}
+
+func (g *targetGroup) contains(ip *IP, port string) bool {
+	return g.index[port] && g.set.ContainsIP(ip)
+}
+
+// Targets lazy generator of parsed targets, every addr is unique and not excluded.
+// ips and ports of every target are crossed while generating, targets without ports yield no addr
+type Targets struct {
+	groups []*targetGroup
+	// excludes exclusions of specified ports
+	excludes []*targetGroup
+	count    *big.Int
+	once     sync.Once
+}
+
+func newTargets(targets []*target) *Targets {
+	ts := &Targets{}
+	all := NewIPSet(nil)
+	for _, t := range targets {
+		if t.exclude {
+			if t.ports == nil {
+				for _, c := range t.cidrs {
+					all.AddCIDR(c)
+				}
+			} else {
+				ts.excludes = append(ts.excludes, newTargetGroup(t.cidrs, t.ports))
+			}
+		}
+	}
+	excluded := all.CIDRs()
+	for _, t := range targets {
+		if t.exclude {
+			continue
+		}
+		g := newTargetGroup(t.cidrs, t.ports)
+		for _, c := range excluded {
+			g.set.RemoveCIDR(c)
+		}
+		g.cidrs = g.set.CIDRs()
+		if len(g.cidrs) > 0 {
+			ts.groups = append(ts.groups, g)
+		}
+	}
+	return ts
+}
+
+// skip addr is excluded or generated by the groups before index
+func (ts *Targets) skip(index int, ip *IP, port string) bool {
+	for _, e := range ts.excludes {
+		if e.contains(ip, port) {
+			return true
+		}
+	}
+	for _, g := range ts.groups[:index] {
+		if g.contains(ip, port) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count return the exact count of addrs.
+// ports with the same targets and exclusions share the same ips, so only one set is built for each of them
+func (ts *Targets) Count() *big.Int {
+	ts.once.Do(func() {
+		ts.count = new(big.Int)
+		var order []string
+		classes := make(map[string][]string)
+		seen := make(map[string]bool)
+		for _, g := range ts.groups {
+			for _, port := range g.ports {
+				if seen[port] {
+					continue
+				}
+				seen[port] = true
+				var sig []byte
+				for _, gs := range [][]*targetGroup{ts.groups, ts.excludes} {
+					for _, other := range gs {
+						if other.index[port] {
+							sig = append(sig, '1')
+						} else {
+							sig = append(sig, '0')
+						}
+					}
+				}
+				if _, ok := classes[string(sig)]; !ok {
+					order = append(order, string(sig))
+				}
+				classes[string(sig)] = append(classes[string(sig)], port)
+			}
+		}
+
+		for _, sig := range order {
+			set := NewIPSet(nil)
+			for i, g := range ts.groups {
+				if sig[i] == '1' {
+					for _, c := range g.cidrs {
+						set.AddCIDR(c)
+					}
+				}
+			}
+			for i, e := range ts.excludes {
+				if sig[len(ts.groups)+i] == '1' {
+					for _, c := range e.set.CIDRs() {
+						set.RemoveCIDR(c)
+					}
+				}
+			}
+			n := set.Count()
+			ts.count.Add(ts.count, n.Mul(n, big.NewInt(int64(len(classes[sig])))))
+		}
+	})
+	return new(big.Int).Set(ts.count)
+}
+
+// CIDRs return all cidrs of targets, including targets without ports, minimal and sorted
+func (ts *Targets) CIDRs() CIDRs {
+	set := NewIPSet(nil)
+	for _, g := range ts.groups {
+		for _, c := range g.cidrs {
+			set.AddCIDR(c)
+		}
+	}
+	return set.CIDRs()
+}
+
+func (ts *Targets) Iterator() *TargetsIterator {
+	return &TargetsIterator{ts: ts}
+}
+
+func (ts *Targets) Range() chan *Addr {
+	return ts.RangeWithContext(context.Background())
+}
+
+func (ts *Targets) RangeWithContext(ctx context.Context) chan *Addr {
+	return RangeAddrIterator(ctx, ts.Iterator())
+}
+
+// TargetsIterator iterate targets one by one, every port of an ip, then next ip
+type TargetsIterator struct {
+	ts    *Targets
+	index int
+	ips   *CIDRsIterator
+	ip    *IP
+	port  int
+}
+
+func (it *TargetsIterator) Next() (*Addr, bool) {
+	for it.index < len(it.ts.groups) {
+		group := it.ts.groups[it.index]
+		if it.ips == nil {
+			if len(group.ports) == 0 {
+				it.index++
+				continue
+			}
+			it.ips = group.cidrs.Iterator()
+		}
+		if it.ip == nil || it.port >= len(group.ports) {
+			ip, ok := it.ips.Next()
+			if !ok {
+				it.index++
+				it.ips, it.ip = nil, nil
+				continue
+			}
+			it.ip, it.port = ip, 0
+		}
+		port := group.ports[it.port]
+		it.port++
+		if it.ts.skip(it.index, it.ip, port) {
+			continue
+		}
+		return &Addr{IP: it.ip, Port: port}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	parser := &TargetParser{
+		Preset:   NewPortPreset([]*PortConfig{{Name: "web", Ports: []string{"80", "443"}, Tags: []string{"top2"}}}),
+		Resolver: NewStaticResolver(map[string][]string{"example.com": {"192.0.2.10"}}),
+	}
+	ts, err := parser.Parse([]string{
+		"10.0.0.0/30:22,top2",
+		"10.0.0.2:80",
+		"example.com:8000-8001",
+		"[2001:db8::1]:22",
+		"2001:db8::100/127",
+		"10.0.1.1-10.0.1.2:21",
+		"!10.0.0.1",
+		"!10.0.0.0/31:443",
+	})
+	assert.NoError(t, err)
+
+	var addrs []string
+	for addr := range ts.Range() {
+		addrs = append(addrs, addr.String())
+	}
+	// every port of an ip, then next ip, target by target. 10.0.0.2:80 is generated only once,
+	// 2001:db8::100/127 has no port and yields no addr
+	assert.Equal(t, []string{
+		"10.0.0.0:22", "10.0.0.0:80", "10.0.0.2:22", "10.0.0.2:80", "10.0.0.2:443",
+		"10.0.0.3:22", "10.0.0.3:80", "10.0.0.3:443",
+		"192.0.2.10:8000", "192.0.2.10:8001",
+		"[2001:db8::1]:22",
+		"10.0.1.1:21", "10.0.1.2:21",
+	}, addrs)
+	assert.Equal(t, int64(len(addrs)), ts.Count().Int64())
+	assert.Equal(t, []string{"10.0.0.0/32", "10.0.0.2/31", "10.0.1.1/32", "10.0.1.2/32", "192.0.2.10/32", "2001:db8::1/128", "2001:db8::100/127"}, ts.CIDRs().Strings())
+}
+
+func TestParseTargets_Errors(t *testing.T) {
+	prePort := PrePort
+	PrePort = nil
+	defer func() { PrePort = prePort }()
+	ts, err := ParseTargets([]string{"10.0.0.0/24:abc", "10.0.0.1/40", "[::1", "10.0.0.1", "# comment"}, "22", "80")
+	errs := err.(ParseErrors)
+	assert.Len(t, errs, 3)
+	assert.True(t, errors.Is(errs[0], ErrPortRange))
+	assert.Equal(t, 12, errs[0].Pos)
+	assert.True(t, errors.Is(errs[1], ErrMaskOutOfRange))
+	assert.Equal(t, 2, errs[1].Line)
+	assert.True(t, errors.Is(errs[2], ErrInvalidAddr))
+
+	// default ports are used by targets without port spec
+	assert.Equal(t, int64(2), ts.Count().Int64())
+	addr, _ := ts.Iterator().Next()
+	assert.Equal(t, "10.0.0.1:22", addr.String())
+
+	ts, err = ParseTargets([]string{"10.0.0.0/16:1-1000", "!10.0.0.0/17"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(32768*1000), ts.Count().Int64())
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := ts.RangeWithContext(ctx)
+	addr = <-ch
+	assert.Equal(t, "10.0.128.0:1", addr.String())
+	cancel()
+}
+
+func TestParseTargets_PresetName(t *testing.T) {
+	parser := &TargetParser{Preset: NewPortPreset([]*PortConfig{{Name: "windows", Ports: []string{"icmp", "445"}, Tags: []string{"win"}}})}
+	ts, err := parser.Parse([]string{"10.0.0.0/31:win"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), ts.Count().Int64())
+}
+
+func TestParseTargets_Overlap(t *testing.T) {
+	ts, err := ParseTargets([]string{"10.0.0.0/24:443", "10.0.0.0/30:80,443", "!10.0.0.1:80"})
+	assert.NoError(t, err)
+	var addrs []string
+	for addr := range ts.Range() {
+		addrs = append(addrs, addr.String())
+	}
+	assert.Len(t, addrs, 259)
+	assert.Equal(t, []string{"10.0.0.255:443", "10.0.0.0:80", "10.0.0.2:80", "10.0.0.3:80"}, addrs[255:])
+	assert.Equal(t, int64(259), ts.Count().Int64())
+
+	// one set of ips is shared by all ports
+	ts, err = ParseTargets([]string{"10.0.0.0/8:1-1000", "!10.0.0.0/24:1-10"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<24*1000-256*10), ts.Count().Int64())
+}
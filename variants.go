@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/chainreactors/utils/iputils"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ParseIPLenient parse ip like inet_aton, accept every form that ParseIP rejects:
+//
+//	3232235777              integer
+//	0xC0A80101, 030052000401 hex and octal integer
+//	0300.0250.1.1, 0xc0.0xa8.1.1, 0xc0.0250.1.1   octal, hex and mixed radix octets
+//	127.1, 10.1.257          short forms, the last part fills the remaining bytes
+//	::ffff:192.168.1.1, ::ffff:c0a8:101, ::192.168.1.1   ipv4-mapped and ipv4-compatible ipv6
+//
+// note that octet with leading zero is octal as inet_aton, 010.0.0.1 is 8.0.0.1
+func ParseIPLenient(s string) (*IP, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+	if strings.Contains(s, ":") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, newParseError(s, 0, ErrInvalidIP)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return NewIP(ip4), nil
+		}
+		// ipv4-compatible ::a.b.c.d, ::1 and other small values are still ipv6
+		if isZeros(ip[:12]) && ip[12] != 0 {
+			return NewIP(net.IP(ip[12:16])), nil
+		}
+		return NewIP(ip), nil
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 4 {
+		return nil, newParseError(s, 0, ErrInvalidIP)
+	}
+	var n uint64
+	pos := 0
+	for i, part := range parts {
+		v, err := parseAtonPart(part)
+		// the last part fills all remaining bytes, others are one byte
+		bits := uint(8)
+		if i == len(parts)-1 {
+			bits = uint(8 * (4 - i))
+		}
+		if err != nil || v >= 1<<bits {
+			return nil, newParseError(s, pos, ErrInvalidIP)
+		}
+		n = n<<bits | v
+		pos += len(part) + 1
+	}
+	return &IP{IP: net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).To4(), Ver: IPV4}, nil
+}
+
+// parseAtonPart parse decimal, octal with leading 0 or hex with 0x prefix
+func parseAtonPart(s string) (uint64, error) {
+	switch {
+	case s == "":
+		return 0, ErrInvalidIP
+	case len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X"):
+		return strconv.ParseUint(s[2:], 16, 32)
+	case len(s) > 1 && s[0] == '0':
+		return strconv.ParseUint(s[1:], 8, 32)
+	default:
+		return strconv.ParseUint(s, 10, 32)
+	}
+}
+
+// formatAtonPart format v in radix 10, 8 or 16 as inet_aton accepts
+func formatAtonPart(v uint64, radix int) string {
+	switch radix {
+	case 8:
+		if v == 0 {
+			return "0"
+		}
+		return "0" + strconv.FormatUint(v, 8)
+	case 16:
+		return "0x" + strconv.FormatUint(v, 16)
+	default:
+		return strconv.FormatUint(v, 10)
+	}
+}
+
+var atonRadixes = []int{10, 8, 16}
+
+// Variants return every representation of ipv4 that inet_aton style parsers accept, canonical form first.
+// integer, hex, octal, mixed radix octets, short forms, ipv4-mapped/compatible ipv6 are all resolved to ip by ParseIPLenient.
+// zero padded decimal forms from iputils.FixedPad and iputils.IncrementalPad are also included,
+// they are the same ip only for parsers that ignore leading zeros, inet_aton treats them as octal.
+// ipv6 has no alternate notation, return the canonical form only
+func (ip *IP) Variants() []string {
+	if ip.Ver != IPV4 {
+		return []string{ip.String()}
+	}
+	ip4 := ip.IP.To4()
+	n := uint64(ip4[0])<<24 | uint64(ip4[1])<<16 | uint64(ip4[2])<<8 | uint64(ip4[3])
+
+	var variants []string
+	seen := make(map[string]bool)
+	add := func(ss ...string) {
+		for _, s := range ss {
+			if !seen[s] {
+				seen[s] = true
+				variants = append(variants, s)
+			}
+		}
+	}
+	add(ip.String())
+
+	// 1 to 4 parts, the last part holds the remaining bytes, every part in every radix
+	for count := 1; count <= 4; count++ {
+		values := make([]uint64, count)
+		for i := 0; i < count-1; i++ {
+			values[i] = n >> uint(24-8*i) & 0xff
+		}
+		values[count-1] = n & (1<<uint(32-8*(count-1)) - 1)
+		for _, radixes := range radixCombinations(count) {
+			parts := make([]string, count)
+			for i, v := range values {
+				parts[i] = formatAtonPart(v, radixes[i])
+			}
+			add(strings.Join(parts, "."))
+		}
+	}
+	add("0x" + strings.ToUpper(strconv.FormatUint(n, 16)))
+
+	ip6 := ip.IP.To16()
+	add(
+		"::ffff:"+ip.String(),
+		iputils.FmtIP4MappedIP6Short(ip6),
+		iputils.FmtIP4MappedIP6(ip6),
+		"0:0:0:0:0:ffff:"+ip.String(),
+		"::"+ip.String(),
+		fmt.Sprintf("::%02x%02x:%02x%02x", ip6[12], ip6[13], ip6[14], ip6[15]),
+	)
+
+	add(iputils.FixedPad(ip4, 3), iputils.FixedPad(ip4, 4))
+	add(iputils.IncrementalPad(ip4, 4)...)
+	return variants
+}
+
+// radixCombinations every combination of radixes for n parts
+func radixCombinations(n int) [][]int {
+	combinations := [][]int{{}}
+	for i := 0; i < n; i++ {
+		var next [][]int
+		for _, c := range combinations {
+			for _, radix := range atonRadixes {
+				next = append(next, append(append([]int{}, c...), radix))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
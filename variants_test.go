@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"github.com/chainreactors/utils/iputils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseIPLenient(t *testing.T) {
+	for _, s := range []string{
+		"192.168.1.1", "3232235777", "0xC0A80101", "0xc0a80101", "030052000401",
+		"0300.0250.1.1", "0300.0250.01.01", "0xc0.0xa8.0x1.0x1", "0xc0.0250.1.0x01",
+		"192.168.257", "192.11010305", "0xc0.0xa80101",
+		"::ffff:192.168.1.1", "::ffff:c0a8:101", "0:0:0:0:0:ffff:c0a8:0101", "::192.168.1.1", "[::ffff:c0a8:101]",
+	} {
+		ip, err := ParseIPLenient(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, "192.168.1.1", ip.String(), s)
+			assert.Equal(t, IPV4, ip.Ver, s)
+		}
+	}
+
+	ip, _ := ParseIPLenient("127.1")
+	assert.Equal(t, "127.0.0.1", ip.String())
+	ip, _ = ParseIPLenient("10.1")
+	assert.Equal(t, "10.0.0.1", ip.String())
+	ip, _ = ParseIPLenient("010.0.0.1")
+	assert.Equal(t, "8.0.0.1", ip.String())
+	ip, _ = ParseIPLenient("::1")
+	assert.Equal(t, IPV6, ip.Ver)
+
+	for _, s := range []string{"256.1.1.1", "1.2.3.4.5", "08.1.1.1", "0x100000000", "1..1", "", "1.2.65536", "a.b.c.d", ":::"} {
+		_, err := ParseIPLenient(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestIP_Variants(t *testing.T) {
+	ip := ParseIP("192.168.1.1")
+	variants := ip.Variants()
+	assert.Equal(t, "192.168.1.1", variants[0])
+	for _, s := range []string{
+		"3232235777", "0xc0a80101", "0xC0A80101", "030052000401", "0300.0250.01.01", "0xc0.0xa8.0x1.0x1",
+		"192.168.257", "192.11010305", "0xc0.0250.1.0x1",
+		"::ffff:192.168.1.1", "::ffff:c0a8:0101", "00:00:00:00:00:ffff:c0a8:0101", "::192.168.1.1",
+		"192.168.001.001", "0192.0168.0001.0001",
+	} {
+		assert.Contains(t, variants, s)
+	}
+
+	// every variant except zero padded decimal is the same ip for inet_aton
+	padded := map[string]bool{iputils.FixedPad(ip.IP, 3): true, iputils.FixedPad(ip.IP, 4): true}
+	for _, s := range iputils.IncrementalPad(ip.IP, 4) {
+		padded[s] = true
+	}
+	for _, v := range variants {
+		if padded[v] && v != "192.168.1.1" {
+			continue
+		}
+		parsed, err := ParseIPLenient(v)
+		if assert.NoError(t, err, v) {
+			assert.Equal(t, "192.168.1.1", parsed.String(), v)
+		}
+	}
+	assert.Equal(t, []string{"2001:db8::1"}, ParseIP("2001:db8::1").Variants())
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sort"
+)
+
+// SubnetRequirement subnet of at least Hosts usable addresses
+type SubnetRequirement struct {
+	Name  string
+	Hosts uint64
+}
+
+// Prefix return the longest prefix of ver that holds Hosts usable addresses,
+// network and broadcast address are reserved for ipv4
+func (r *SubnetRequirement) Prefix(ver int) (int, error) {
+	if r.Hosts == 0 {
+		return 0, fmt.Errorf("subnet %s requires 0 hosts", r.Name)
+	}
+	n := r.Hosts
+	total := 128
+	if ver == IPV4 {
+		total = 32
+		if n > 1<<32-2 {
+			return 0, fmt.Errorf("subnet %s requires %d hosts, more than ipv4 address space", r.Name, r.Hosts)
+		}
+		n += 2
+	}
+	return total - bits.Len64(n-1), nil
+}
+
+// SubnetAllocation subnet allocated for requirement
+type SubnetAllocation struct {
+	*SubnetRequirement
+	CIDR *CIDR
+	// Usable count of usable host addresses of CIDR
+	Usable *big.Int
+}
+
+func (a *SubnetAllocation) String() string {
+	return fmt.Sprintf("%s %s (%d/%s hosts)", a.Name, a.CIDR.String(), a.Hosts, a.Usable.String())
+}
+
+// SubnetPlan VLSM allocation of parent, Subnets are sorted by address, Free is the remaining blocks
+type SubnetPlan struct {
+	Parent  *CIDR
+	Subnets []*SubnetAllocation
+	Free    CIDRs
+}
+
+// SubnetPlanError requirement not fit in the remaining space of parent
+type SubnetPlanError struct {
+	Parent      *CIDR
+	Requirement *SubnetRequirement
+	Prefix      int
+	Free        CIDRs
+}
+
+func (e *SubnetPlanError) Error() string {
+	return fmt.Sprintf("%s has no space for subnet %s of %d hosts (/%d), free: %v",
+		e.Parent.String(), e.Requirement.Name, e.Requirement.Hosts, e.Prefix, e.Free.Strings())
+}
+
+// PlanSubnets carve parent into subnets of requirements, the largest requirement is allocated first at
+// the lowest aligned address, so that subnets are packed without gaps
+func PlanSubnets(parent *CIDR, reqs []*SubnetRequirement) (*SubnetPlan, error) {
+	type pending struct {
+		req    *SubnetRequirement
+		prefix int
+	}
+	ps := make([]pending, len(reqs))
+	for i, r := range reqs {
+		prefix, err := r.Prefix(parent.Ver)
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = pending{req: r, prefix: prefix}
+	}
+	sort.SliceStable(ps, func(i, j int) bool {
+		return ps[i].prefix < ps[j].prefix
+	})
+
+	plan := &SubnetPlan{Parent: parent.FirstIP().CIDR(parent.Mask)}
+	// free blocks sorted by address, every block is aligned
+	free := CIDRs{plan.Parent}
+	for _, p := range ps {
+		i := 0
+		for ; i < len(free) && free[i].Mask > p.prefix; i++ {
+		}
+		if i == len(free) {
+			return nil, &SubnetPlanError{Parent: plan.Parent, Requirement: p.req, Prefix: p.prefix, Free: free}
+		}
+
+		// halve the block until it fits, the upper halves are left free
+		block := free[i]
+		var rest CIDRs
+		for block.Mask < p.prefix {
+			halves, err := block.Split(block.Mask + 1)
+			if err != nil {
+				return nil, err
+			}
+			block = halves[0]
+			rest = append(CIDRs{halves[1]}, rest...)
+		}
+		free = append(free[:i], append(rest, free[i+1:]...)...)
+
+		plan.Subnets = append(plan.Subnets, &SubnetAllocation{
+			SubnetRequirement: p.req,
+			CIDR:              block,
+			Usable:            block.Info().Usable,
+		})
+	}
+
+	sort.SliceStable(plan.Subnets, func(i, j int) bool {
+		return plan.Subnets[i].CIDR.Compare(plan.Subnets[j].CIDR) < 0
+	})
+	used := make(CIDRs, len(plan.Subnets))
+	for i, s := range plan.Subnets {
+		used[i] = s.CIDR
+	}
+	free, err := plan.Parent.FreeSpace(used)
+	if err != nil {
+		return nil, err
+	}
+	plan.Free = free
+	return plan, nil
+}
+
+// Plan see PlanSubnets
+func (c *CIDR) Plan(reqs ...*SubnetRequirement) (*SubnetPlan, error) {
+	return PlanSubnets(c, reqs)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlanSubnets(t *testing.T) {
+	plan, err := ParseCIDR("10.0.0.0/22").Plan(
+		&SubnetRequirement{Name: "dmz", Hosts: 60},
+		&SubnetRequirement{Name: "office", Hosts: 500},
+		&SubnetRequirement{Name: "lab", Hosts: 120},
+		&SubnetRequirement{Name: "p2p", Hosts: 2},
+	)
+	assert.NoError(t, err)
+	var got []string
+	for _, s := range plan.Subnets {
+		got = append(got, s.Name+" "+s.CIDR.String())
+	}
+	assert.Equal(t, []string{"office 10.0.0.0/23", "lab 10.0.2.0/25", "dmz 10.0.2.128/26", "p2p 10.0.2.192/30"}, got)
+	assert.Equal(t, "510", plan.Subnets[0].Usable.String())
+	assert.Equal(t, []string{"10.0.2.196/30", "10.0.2.200/29", "10.0.2.208/28", "10.0.2.224/27", "10.0.3.0/24"}, plan.Free.Strings())
+
+	// exact fit
+	plan, err = ParseCIDR("192.168.0.0/24").Plan(
+		&SubnetRequirement{Name: "a", Hosts: 126},
+		&SubnetRequirement{Name: "b", Hosts: 126},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.0.128/25", plan.Subnets[1].CIDR.String())
+	assert.Len(t, plan.Free, 0)
+
+	plan, err = ParseCIDR("2001:db8::/48").Plan(
+		&SubnetRequirement{Name: "lan", Hosts: 1<<63 + 1},
+		&SubnetRequirement{Name: "mgmt", Hosts: 256},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::/64", plan.Subnets[0].CIDR.String())
+	assert.Equal(t, "2001:db8:0:1::/120", plan.Subnets[1].CIDR.String())
+}
+
+func TestPlanSubnets_Error(t *testing.T) {
+	_, err := ParseCIDR("192.168.0.0/24").Plan(
+		&SubnetRequirement{Name: "a", Hosts: 127},
+		&SubnetRequirement{Name: "b", Hosts: 100},
+	)
+	if assert.Error(t, err) {
+		perr, ok := err.(*SubnetPlanError)
+		assert.True(t, ok)
+		assert.Equal(t, "b", perr.Requirement.Name)
+		assert.Equal(t, 25, perr.Prefix)
+		assert.Equal(t, "192.168.0.0/24 has no space for subnet b of 100 hosts (/25), free: []", err.Error())
+	}
+
+	_, err = ParseCIDR("10.0.0.0/8").Plan(&SubnetRequirement{Name: "zero"})
+	assert.Error(t, err)
+
+	prefix, _ := (&SubnetRequirement{Hosts: 1}).Prefix(IPV4)
+	assert.Equal(t, 30, prefix)
+	prefix, _ = (&SubnetRequirement{Hosts: 1}).Prefix(IPV6)
+	assert.Equal(t, 128, prefix)
+}